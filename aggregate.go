@@ -0,0 +1,230 @@
+package walker
+
+import (
+	"iter"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// DirCounts walks the tree and returns a map of each matching directory
+// to the number of matching files it directly contains.
+// Files in subdirectories are not counted toward their ancestors.
+func (tr *Ranger) DirCounts() (map[string]int, error) {
+	counts := make(map[string]int)
+	for e := range tr.Entries() {
+		if e.IsDir() {
+			counts[e.Path] += 0
+			continue
+		}
+		counts[e.Dir()]++
+	}
+	return counts, tr.Err()
+}
+
+// FilesInCrowdedDirs returns a sequence of the matching files
+// in directories that directly contain more than threshold matching files.
+// It walks the tree once to count the files in each directory with DirCounts,
+// returning any error from that walk,
+// and walks it again when the sequence is iterated.
+// Check Err after iterating for errors during the second walk.
+// If the tree changes between walks, the counts may be stale.
+func (tr *Ranger) FilesInCrowdedDirs(threshold int) (iter.Seq[Entry], error) {
+	counts, err := tr.DirCounts()
+	if err != nil {
+		return nil, err
+	}
+	return func(yield func(Entry) bool) {
+		for e := range tr.FileEntries() {
+			if counts[e.Dir()] > threshold && !yield(e) {
+				return
+			}
+		}
+	}, nil
+}
+
+// NewestPerDir walks the tree and returns a sequence of
+// the most recently modified matching file in each directory,
+// in the order the directories were walked,
+// along with the Ranger's Err().
+// If files in a directory share the newest modification time,
+// the first in lexical order is chosen.
+// Files whose modification time cannot be read are not considered.
+func (tr *Ranger) NewestPerDir() (iter.Seq[Entry], error) {
+	type newest struct {
+		Entry
+		modTime time.Time
+	}
+	var dirs []string
+	best := make(map[string]newest)
+	for e := range tr.FileEntries() {
+		t, ok := modTime(e)
+		if !ok {
+			continue
+		}
+		dir := e.Dir()
+		prev, seen := best[dir]
+		if !seen {
+			dirs = append(dirs, dir)
+		}
+		if !seen || t.After(prev.modTime) {
+			best[dir] = newest{e, t}
+		}
+	}
+	return func(yield func(Entry) bool) {
+		for _, dir := range dirs {
+			if !yield(best[dir].Entry) {
+				return
+			}
+		}
+	}, tr.Err()
+}
+
+// Summary holds statistics about a walk.
+type Summary struct {
+	Files, Dirs int
+	// Bytes is the total size of the matching files.
+	Bytes int64
+	// Extensions counts matching files by lowercase extension.
+	// Files without an extension are counted under "".
+	Extensions map[string]int
+	// Errors is the number of errors passed to the ErrorPolicy.
+	Errors int
+}
+
+// Summary walks the tree and returns statistics about the matching entries.
+func (tr *Ranger) Summary() (Summary, error) {
+	s := Summary{Extensions: make(map[string]int)}
+	erp := tr.erp
+	defer func() { tr.erp = erp }()
+	tr.erp = func(err error, e Entry) bool {
+		s.Errors++
+		return erp(err, e)
+	}
+	for e := range tr.Entries() {
+		if e.IsDir() {
+			s.Dirs++
+			continue
+		}
+		s.Files++
+		n, _ := size(e)
+		s.Bytes += n
+		s.Extensions[strings.ToLower(e.Ext())]++
+	}
+	return s, tr.Err()
+}
+
+// DirStat holds recursive statistics about a directory.
+type DirStat struct {
+	// FileCount is the number of matching files
+	// in the directory and all of its subdirectories.
+	FileCount int
+	// TotalSize is the total size of those files.
+	TotalSize int64
+}
+
+// DirStats walks the tree and returns a map of each directory
+// that passes the directory filters to its recursive DirStat.
+// Unlike DirCounts, files in subdirectories count toward all of their ancestors.
+// The stats are accumulated as the walk leaves each directory,
+// so only one branch of the tree is held in memory at a time,
+// and the tree is always walked depth first, even if BreadthFirst is set.
+func (tr *Ranger) DirStats() (map[string]DirStat, error) {
+	stats := make(map[string]DirStat)
+	// The callback never fails, so the only error is tr.Err().
+	_ = tr.ForEachDir(func(dir Entry, files []Entry) error {
+		s := stats[dir.Path]
+		for _, f := range files {
+			n, _ := size(f)
+			s.FileCount++
+			s.TotalSize += n
+		}
+		stats[dir.Path] = s
+		if dir.RelRoot() != "." {
+			parent := stats[dir.parent()]
+			parent.FileCount += s.FileCount
+			parent.TotalSize += s.TotalSize
+			stats[dir.parent()] = parent
+		}
+		return nil
+	})
+	return stats, tr.Err()
+}
+
+// AgeBuckets walks the tree and counts matching files by age,
+// measured from their modification time to the current time of the Ranger's Clock.
+// Each file is counted in the bucket of the smallest bound its age is less than,
+// labeled like "<7d", or in a final bucket labeled like ">=30d"
+// if it is at least as old as every bound.
+// Bounds that are a whole number of days are labeled in days;
+// others use time.Duration.String.
+// The bounds are sorted before use, so they may be given in any order.
+// Files whose modification time cannot be read are not counted.
+func (tr *Ranger) AgeBuckets(bounds []time.Duration) (map[string]int, error) {
+	bounds = slices.Clone(bounds)
+	slices.Sort(bounds)
+	labels := make([]string, len(bounds)+1)
+	for i, d := range bounds {
+		labels[i] = "<" + formatAge(d)
+	}
+	if len(bounds) > 0 {
+		labels[len(bounds)] = ">=" + formatAge(bounds[len(bounds)-1])
+	}
+	now := tr.currentTime()
+	buckets := make(map[string]int)
+	for e := range tr.FileEntries() {
+		t, ok := modTime(e)
+		if !ok {
+			continue
+		}
+		age := now.Sub(t)
+		i := 0
+		for i < len(bounds) && age >= bounds[i] {
+			i++
+		}
+		buckets[labels[i]]++
+	}
+	return buckets, tr.Err()
+}
+
+// formatAge formats d in days if it is a whole number of days.
+func formatAge(d time.Duration) string {
+	const day = 24 * time.Hour
+	if d != 0 && d%day == 0 {
+		return strconv.FormatInt(int64(d/day), 10) + "d"
+	}
+	return d.String()
+}
+
+// CommonDir walks the tree and returns the deepest directory
+// that contains all of the matching files.
+// Directories are compared by path segment,
+// so "dir1" and "dir10" have no common directory besides the root.
+// If the matching files have no deeper common directory,
+// or there are no matching files, it returns the root.
+func (tr *Ranger) CommonDir() (string, error) {
+	var common []string
+	first := true
+	for e := range tr.FileEntries() {
+		segs := e.relSegments()
+		if len(segs) > 0 {
+			segs = segs[:len(segs)-1]
+		}
+		if first {
+			common = segs
+			first = false
+			continue
+		}
+		n := 0
+		for n < len(common) && n < len(segs) && common[n] == segs[n] {
+			n++
+		}
+		common = common[:n]
+	}
+	dir := tr.root
+	for _, seg := range common {
+		dir = tr.join(dir, seg)
+	}
+	return dir, tr.Err()
+}
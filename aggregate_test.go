@@ -0,0 +1,214 @@
+package walker_test
+
+import (
+	"strings"
+	"testing"
+	"testing/fstest"
+	"time"
+
+	"github.com/carlmjohnson/be"
+	"github.com/earthboundkid/walker"
+)
+
+func TestRanger_DirCounts(t *testing.T) {
+	testFS := fstest.MapFS{
+		"a.txt":                &fstest.MapFile{},
+		"dir1/file3.txt":       &fstest.MapFile{},
+		"dir1/file4.log":       &fstest.MapFile{},
+		"dir2/file5.txt":       &fstest.MapFile{},
+		"dir2/subdir/file6.go": &fstest.MapFile{},
+		"file1.txt":            &fstest.MapFile{},
+		"file2.log":            &fstest.MapFile{},
+	}
+	tr := walker.New(testFS, ".", walker.OnErrorHalt)
+	counts, err := tr.DirCounts()
+	be.NilErr(t, err)
+	be.Equal(t, 4, len(counts))
+	be.Equal(t, 3, counts["."])
+	be.Equal(t, 2, counts["dir1"])
+	be.Equal(t, 1, counts["dir2"])
+	be.Equal(t, 1, counts["dir2/subdir"])
+
+	tr.Include(walker.MatchExtension(".txt"))
+	counts, err = tr.DirCounts()
+	be.NilErr(t, err)
+	be.Equal(t, 2, counts["."])
+	be.Equal(t, 1, counts["dir1"])
+	be.Equal(t, 0, counts["dir2/subdir"])
+}
+
+func TestRanger_Summary(t *testing.T) {
+	testFS := fstest.MapFS{
+		"a.txt":                &fstest.MapFile{Data: []byte("a")},
+		"dir1/file3.txt":       &fstest.MapFile{Data: []byte("bb")},
+		"dir1/file4.log":       &fstest.MapFile{Data: []byte("ccc")},
+		"dir2/file5.TXT":       &fstest.MapFile{Data: []byte("dddd")},
+		"dir2/subdir/file6.go": &fstest.MapFile{Data: []byte("eeeee")},
+		"dir2/subdir/Makefile": &fstest.MapFile{},
+	}
+	tr := walker.New(testFS, ".", walker.OnErrorHalt)
+	s, err := tr.Summary()
+	be.NilErr(t, err)
+	be.Equal(t, 6, s.Files)
+	be.Equal(t, 4, s.Dirs)
+	be.Equal(t, 15, s.Bytes)
+	be.Equal(t, 0, s.Errors)
+	be.Equal(t, 4, len(s.Extensions))
+	be.Equal(t, 3, s.Extensions[".txt"])
+	be.Equal(t, 1, s.Extensions[".log"])
+	be.Equal(t, 1, s.Extensions[".go"])
+	be.Equal(t, 1, s.Extensions[""])
+}
+
+func TestRanger_Summary_errors(t *testing.T) {
+	dir := tempDirWithPermErr(t)
+	tr := walker.New(nil, dir, walker.OnErrorIgnore)
+	s, err := tr.Summary()
+	be.NilErr(t, err)
+	be.Equal(t, 2, s.Files)
+	be.Equal(t, 1, s.Errors)
+}
+
+func TestRanger_DirStats(t *testing.T) {
+	testFS := fstest.MapFS{
+		"a.txt":                &fstest.MapFile{Data: []byte("a")},
+		"dir1/file3.txt":       &fstest.MapFile{Data: []byte("bb")},
+		"dir1/file4.log":       &fstest.MapFile{Data: []byte("ccc")},
+		"dir2/file5.txt":       &fstest.MapFile{Data: []byte("dddd")},
+		"dir2/subdir/file6.go": &fstest.MapFile{Data: []byte("eeeee")},
+	}
+	tr := walker.New(testFS, ".", walker.OnErrorHalt)
+	stats, err := tr.DirStats()
+	be.NilErr(t, err)
+	be.Equal(t, 4, len(stats))
+	be.Equal(t, walker.DirStat{FileCount: 5, TotalSize: 15}, stats["."])
+	be.Equal(t, walker.DirStat{FileCount: 2, TotalSize: 5}, stats["dir1"])
+	be.Equal(t, walker.DirStat{FileCount: 2, TotalSize: 9}, stats["dir2"])
+	be.Equal(t, walker.DirStat{FileCount: 1, TotalSize: 5}, stats["dir2/subdir"])
+
+	tr.BreadthFirst(true)
+	bfStats, err := tr.DirStats()
+	be.NilErr(t, err)
+	be.Equal(t, len(stats), len(bfStats))
+	for dir, stat := range stats {
+		be.Equal(t, stat, bfStats[dir])
+	}
+	tr.BreadthFirst(false)
+
+	tr.Include(walker.MatchExtension(".txt"))
+	tr.ExcludeDir(walker.MatchGlobName("dir1"))
+	stats, err = tr.DirStats()
+	be.NilErr(t, err)
+	be.Equal(t, 3, len(stats))
+	be.Equal(t, walker.DirStat{FileCount: 2, TotalSize: 5}, stats["."])
+	be.Equal(t, walker.DirStat{FileCount: 1, TotalSize: 4}, stats["dir2"])
+	be.Equal(t, walker.DirStat{}, stats["dir2/subdir"])
+}
+
+func TestRanger_AgeBuckets(t *testing.T) {
+	now := time.Date(2024, 6, 1, 12, 0, 0, 0, time.UTC)
+	day := 24 * time.Hour
+	testFS := fstest.MapFS{
+		"new.txt":      &fstest.MapFile{ModTime: now.Add(-time.Hour)},
+		"future.txt":   &fstest.MapFile{ModTime: now.Add(time.Hour)},
+		"dir1/a.txt":   &fstest.MapFile{ModTime: now.Add(-2 * day)},
+		"dir1/b.txt":   &fstest.MapFile{ModTime: now.Add(-7 * day)},
+		"dir2/c.txt":   &fstest.MapFile{ModTime: now.Add(-10 * day)},
+		"dir2/old.txt": &fstest.MapFile{ModTime: now.Add(-365 * day)},
+	}
+	tr := walker.New(testFS, ".", walker.OnErrorHalt)
+	tr.Clock(func() time.Time { return now })
+	buckets, err := tr.AgeBuckets([]time.Duration{30 * day, day, 7 * day})
+	be.NilErr(t, err)
+	be.Equal(t, 4, len(buckets))
+	be.Equal(t, 2, buckets["<1d"])
+	be.Equal(t, 1, buckets["<7d"])
+	be.Equal(t, 2, buckets["<30d"])
+	be.Equal(t, 1, buckets[">=30d"])
+
+	buckets, err = tr.AgeBuckets([]time.Duration{90 * time.Minute})
+	be.NilErr(t, err)
+	be.Equal(t, 2, buckets["<1h30m0s"])
+	be.Equal(t, 4, buckets[">=1h30m0s"])
+}
+
+func TestRanger_FilesInCrowdedDirs(t *testing.T) {
+	testFS := fstest.MapFS{
+		"a.txt":          &fstest.MapFile{},
+		"crowded/1.txt":  &fstest.MapFile{},
+		"crowded/2.txt":  &fstest.MapFile{},
+		"crowded/3.txt":  &fstest.MapFile{},
+		"crowded/sub/4":  &fstest.MapFile{},
+		"sparse/5.txt":   &fstest.MapFile{},
+		"sparse/6.txt":   &fstest.MapFile{},
+		"sparse/sub/7.x": &fstest.MapFile{},
+	}
+	tr := walker.New(testFS, ".", walker.OnErrorHalt)
+	seq, err := tr.FilesInCrowdedDirs(2)
+	be.NilErr(t, err)
+	var paths []string
+	for e := range seq {
+		paths = append(paths, e.Path)
+	}
+	be.NilErr(t, tr.Err())
+	be.Equal(t, "crowded/1.txt; crowded/2.txt; crowded/3.txt", strings.Join(paths, "; "))
+
+	tr = walker.New(testFS, "missing", walker.OnErrorHalt)
+	_, err = tr.FilesInCrowdedDirs(2)
+	be.Nonzero(t, err)
+}
+
+func TestRanger_CommonDir(t *testing.T) {
+	testFS := fstest.MapFS{
+		"a.txt":                 &fstest.MapFile{},
+		"dir1/file3.txt":        &fstest.MapFile{},
+		"dir10/file4.txt":       &fstest.MapFile{},
+		"dir2/file5.txt":        &fstest.MapFile{},
+		"dir2/subdir/file6.go":  &fstest.MapFile{},
+		"dir2/subdir/file7.go":  &fstest.MapFile{},
+		"dir2/subdir2/file8.md": &fstest.MapFile{},
+	}
+	for _, tc := range []struct {
+		root    string
+		include walker.FilterFunc
+		want    string
+	}{
+		{".", walker.MatchGlobPath("dir2/*", "dir2/*/*"), "dir2"},
+		{".", walker.MatchExtension(".go"), "dir2/subdir"},
+		{".", walker.MatchGlobName("file6.go"), "dir2/subdir"},
+		{".", walker.MatchGlobPath("dir1/*", "dir10/*"), "."},
+		{".", walker.MatchExtension(".txt"), "."},
+		{".", walker.MatchExtension(".none"), "."},
+		{"dir2", walker.MatchExtension(".go", ".md"), "dir2"},
+	} {
+		tr := walker.New(testFS, tc.root, walker.OnErrorHalt)
+		tr.Include(tc.include)
+		dir, err := tr.CommonDir()
+		be.NilErr(t, err)
+		be.Equal(t, tc.want, dir)
+	}
+}
+
+func TestRanger_NewestPerDir(t *testing.T) {
+	now := time.Date(2024, 6, 1, 12, 0, 0, 0, time.UTC)
+	testFS := fstest.MapFS{
+		"a.log":          &fstest.MapFile{ModTime: now.Add(-time.Hour)},
+		"b.log":          &fstest.MapFile{ModTime: now},
+		"logs/app.1.log": &fstest.MapFile{ModTime: now.Add(-3 * time.Hour)},
+		"logs/app.2.log": &fstest.MapFile{ModTime: now.Add(-time.Hour)},
+		"logs/app.log":   &fstest.MapFile{ModTime: now.Add(-2 * time.Hour)},
+		"logs/old/x.log": &fstest.MapFile{ModTime: now},
+		"logs/old/y.log": &fstest.MapFile{ModTime: now},
+		"logs/old/z.txt": &fstest.MapFile{ModTime: now.Add(time.Hour)},
+		"other/only.log": &fstest.MapFile{ModTime: now},
+	}
+	tr := walker.New(testFS, ".", walker.OnErrorHalt)
+	tr.Include(walker.MatchExtension(".log"))
+	seq, err := tr.NewestPerDir()
+	be.NilErr(t, err)
+	var paths []string
+	for e := range seq {
+		paths = append(paths, e.Path)
+	}
+	be.Equal(t, "b.log; logs/app.2.log; logs/old/x.log; other/only.log", strings.Join(paths, "; "))
+}
@@ -0,0 +1,73 @@
+package walker
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+)
+
+// archiveName returns the slash separated path relative to the walk root,
+// with a trailing slash for directories, as used by tar and zip.
+func (e Entry) archiveName() string {
+	name := filepath.ToSlash(e.RelRoot())
+	if e.IsDir() {
+		name += "/"
+	}
+	return name
+}
+
+// readLink returns the target of the symbolic link e.
+func (e Entry) readLink() (string, error) {
+	switch {
+	case e.useFilepath:
+		return os.Readlink(e.Path)
+	case e.fsys != nil:
+		if rl, ok := e.fsys.(interface {
+			ReadLink(name string) (string, error)
+		}); ok {
+			return rl.ReadLink(e.Path)
+		}
+	}
+	return "", &fs.PathError{Op: "readlink", Path: e.Path, Err: errors.ErrUnsupported}
+}
+
+// TarHeader returns a tar.Header for the Entry, populated by tar.FileInfoHeader,
+// with its Name set to the slash separated path relative to the walk root.
+// If the Entry is a symbolic link, the link target is read and set as Linkname.
+func (e Entry) TarHeader() (*tar.Header, error) {
+	info, err := e.Info()
+	if err != nil {
+		return nil, err
+	}
+	var link string
+	if e.IsSymlink() {
+		if link, err = e.readLink(); err != nil {
+			return nil, err
+		}
+	}
+	h, err := tar.FileInfoHeader(info, link)
+	if err != nil {
+		return nil, err
+	}
+	h.Name = e.archiveName()
+	return h, nil
+}
+
+// ZipHeader returns a zip.FileHeader for the Entry, populated by zip.FileInfoHeader,
+// with its Name set to the slash separated path relative to the walk root.
+// The compression method is left as zip.Store; set Method to compress the file.
+func (e Entry) ZipHeader() (*zip.FileHeader, error) {
+	info, err := e.Info()
+	if err != nil {
+		return nil, err
+	}
+	h, err := zip.FileInfoHeader(info)
+	if err != nil {
+		return nil, err
+	}
+	h.Name = e.archiveName()
+	return h, nil
+}
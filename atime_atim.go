@@ -0,0 +1,14 @@
+//go:build linux || openbsd
+
+package walker
+
+import "time"
+
+// accessTime returns the last access time of e, if available.
+func accessTime(e Entry) (time.Time, bool) {
+	st, ok := statT(e)
+	if !ok {
+		return modTime(e)
+	}
+	return time.Unix(st.Atim.Unix()), true
+}
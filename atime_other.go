@@ -0,0 +1,11 @@
+//go:build !(linux || openbsd || darwin || freebsd || netbsd)
+
+package walker
+
+import "time"
+
+// accessTime returns the modification time of e,
+// because access times are not supported on this platform.
+func accessTime(e Entry) (time.Time, bool) {
+	return modTime(e)
+}
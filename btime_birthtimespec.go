@@ -0,0 +1,14 @@
+//go:build darwin || freebsd || netbsd
+
+package walker
+
+import "time"
+
+// birthTime returns the creation time of e, if available.
+func birthTime(e Entry) (time.Time, bool) {
+	st, ok := statT(e)
+	if !ok {
+		return time.Time{}, false
+	}
+	return time.Unix(st.Birthtimespec.Unix()), true
+}
@@ -0,0 +1,22 @@
+package walker
+
+import (
+	"time"
+
+	"golang.org/x/sys/unix"
+)
+
+// birthTime returns the creation time of e, if available.
+// It calls statx, so it only works when walking the OS filesystem
+// on Linux 4.11 or later with a filesystem that records creation times.
+func birthTime(e Entry) (time.Time, bool) {
+	if !e.useFilepath || e.Path == "" {
+		return time.Time{}, false
+	}
+	var stx unix.Statx_t
+	err := unix.Statx(unix.AT_FDCWD, e.Path, unix.AT_SYMLINK_NOFOLLOW, unix.STATX_BTIME, &stx)
+	if err != nil || stx.Mask&unix.STATX_BTIME == 0 {
+		return time.Time{}, false
+	}
+	return time.Unix(stx.Btime.Sec, int64(stx.Btime.Nsec)), true
+}
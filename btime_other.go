@@ -0,0 +1,11 @@
+//go:build !darwin && !freebsd && !linux && !netbsd && !windows
+
+package walker
+
+import "time"
+
+// birthTime returns the creation time of e, if available.
+// Creation times are not available through package syscall on this platform.
+func birthTime(e Entry) (time.Time, bool) {
+	return time.Time{}, false
+}
@@ -0,0 +1,22 @@
+package walker
+
+import (
+	"syscall"
+	"time"
+)
+
+// birthTime returns the creation time of e, if available.
+func birthTime(e Entry) (time.Time, bool) {
+	if e.DirEntry == nil {
+		return time.Time{}, false
+	}
+	info, err := e.DirEntry.Info()
+	if err != nil {
+		return time.Time{}, false
+	}
+	attrs, ok := info.Sys().(*syscall.Win32FileAttributeData)
+	if !ok {
+		return time.Time{}, false
+	}
+	return time.Unix(0, attrs.CreationTime.Nanoseconds()), true
+}
@@ -0,0 +1,105 @@
+package walker
+
+import (
+	"context"
+	"iter"
+	"sync"
+)
+
+// Channel walks the tree in a new goroutine
+// and sends matching entries on the returned entry channel,
+// buffering up to buf entries ahead of the receiver.
+// When the walk is done, the entry channel is closed
+// and the Ranger's Err() is sent on the error channel before it is closed.
+// If ctx is canceled before the walk is done,
+// the goroutine stops walking and sends ctx.Err() instead,
+// without sending any further entries, even if there is room in the buffer.
+// The Ranger must not be used by other goroutines until the error channel is closed.
+func (tr *Ranger) Channel(ctx context.Context, buf int) (<-chan Entry, <-chan error) {
+	entries := make(chan Entry, buf)
+	errc := make(chan error, 1)
+	go func() {
+		defer close(errc)
+		defer close(entries)
+		for e := range tr.Entries() {
+			if err := ctx.Err(); err != nil {
+				errc <- err
+				return
+			}
+			select {
+			case entries <- e:
+			case <-ctx.Done():
+				errc <- ctx.Err()
+				return
+			}
+		}
+		errc <- tr.Err()
+	}()
+	return entries, errc
+}
+
+// Tee returns n sequences which each yield every matching entry
+// from a single walk of the tree.
+//
+// The walk runs in a new goroutine,
+// started when any of the sequences is first iterated,
+// and hands each entry to every sequence in turn without buffering,
+// so the walk proceeds at the pace of the slowest consumer.
+// The sequences must therefore be iterated concurrently,
+// each in its own goroutine,
+// and each must be iterated exactly once;
+// a sequence which is never iterated blocks the walk forever.
+// A consumer may stop early,
+// after which it is skipped,
+// and the walk stops once every consumer has stopped.
+// Each sequence returns only after the walk goroutine has exited,
+// so a consumer which stops early waits for the others to finish.
+// The Ranger must not be used by other goroutines until every sequence is done,
+// after which Err() reports any error from the walk.
+func (tr *Ranger) Tee(n int) []iter.Seq[Entry] {
+	chans := make([]chan Entry, n)
+	stopped := make([]chan struct{}, n)
+	for i := range n {
+		chans[i] = make(chan Entry)
+		stopped[i] = make(chan struct{})
+	}
+	var start sync.Once
+	done := make(chan struct{})
+	walk := func() {
+		defer close(done)
+		defer func() {
+			for _, ch := range chans {
+				close(ch)
+			}
+		}()
+		for e := range tr.Entries() {
+			live := 0
+			for i, ch := range chans {
+				select {
+				case ch <- e:
+					live++
+				case <-stopped[i]:
+				}
+			}
+			if live == 0 {
+				return
+			}
+		}
+	}
+	seqs := make([]iter.Seq[Entry], n)
+	for i := range n {
+		seqs[i] = func(yield func(Entry) bool) {
+			start.Do(func() { go walk() })
+			defer func() {
+				close(stopped[i])
+				<-done
+			}()
+			for e := range chans[i] {
+				if !yield(e) {
+					return
+				}
+			}
+		}
+	}
+	return seqs
+}
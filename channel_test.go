@@ -0,0 +1,121 @@
+package walker_test
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"strings"
+	"sync"
+	"testing"
+	"testing/fstest"
+	"time"
+
+	"github.com/carlmjohnson/be"
+	"github.com/earthboundkid/walker"
+)
+
+func TestRanger_Channel(t *testing.T) {
+	testFS := fstest.MapFS{
+		"a.txt":                &fstest.MapFile{},
+		"dir1/file3.txt":       &fstest.MapFile{},
+		"dir1/file4.log":       &fstest.MapFile{},
+		"dir2/file5.txt":       &fstest.MapFile{},
+		"dir2/subdir/file6.go": &fstest.MapFile{},
+		"file1.txt":            &fstest.MapFile{},
+		"file2.log":            &fstest.MapFile{},
+	}
+	tr := walker.New(testFS, ".", walker.OnErrorHalt)
+	tr.Include(walker.MatchExtension(".txt"))
+
+	entries, errc := tr.Channel(context.Background(), 1)
+	var paths []string
+	for e := range entries {
+		time.Sleep(time.Millisecond)
+		paths = append(paths, e.Path)
+	}
+	be.NilErr(t, <-errc)
+	be.Equal(t, "a.txt; dir1/file3.txt; dir2/file5.txt; file1.txt", strings.Join(paths, "; "))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	entries, errc = tr.Channel(ctx, 1)
+	e := <-entries
+	be.Equal(t, "a.txt", e.Path)
+	cancel()
+	be.True(t, errors.Is(<-errc, context.Canceled))
+	for range entries {
+	}
+
+	// Once ctx is canceled, no further entries are sent,
+	// even though the buffer has room for them.
+	for range 10 {
+		ctx, cancel = context.WithCancel(context.Background())
+		tr = walker.New(testFS, ".", walker.OnErrorHalt)
+		tr.Include(func(e walker.Entry) bool {
+			if e.Path == "dir1/file3.txt" {
+				cancel()
+			}
+			return walker.MatchExtension(".txt")(e)
+		})
+		entries, errc = tr.Channel(ctx, 10)
+		paths = nil
+		for e := range entries {
+			paths = append(paths, e.Path)
+		}
+		be.True(t, errors.Is(<-errc, context.Canceled))
+		be.Equal(t, "a.txt", strings.Join(paths, "; "))
+	}
+}
+
+func TestRanger_Tee(t *testing.T) {
+	testFS := fstest.MapFS{
+		"a.txt":                &fstest.MapFile{},
+		"dir1/file3.txt":       &fstest.MapFile{},
+		"dir1/file4.log":       &fstest.MapFile{},
+		"dir2/file5.txt":       &fstest.MapFile{},
+		"dir2/subdir/file6.go": &fstest.MapFile{},
+		"file1.txt":            &fstest.MapFile{},
+	}
+	tr := walker.New(testFS, ".", walker.OnErrorHalt)
+	tr.Include(walker.MatchExtension(".txt"))
+
+	seqs := tr.Tee(3)
+	got := make([][]string, len(seqs))
+	var wg sync.WaitGroup
+	for i, seq := range seqs {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for e := range seq {
+				if i == 0 {
+					time.Sleep(time.Millisecond)
+				}
+				got[i] = append(got[i], e.Path)
+				// The last consumer stops early.
+				if i == 2 && len(got[i]) == 2 {
+					return
+				}
+			}
+		}()
+	}
+	wg.Wait()
+	be.NilErr(t, tr.Err())
+	be.Equal(t, "a.txt; dir1/file3.txt; dir2/file5.txt; file1.txt", strings.Join(got[0], "; "))
+	be.Equal(t, strings.Join(got[0], "; "), strings.Join(got[1], "; "))
+	be.Equal(t, "a.txt; dir1/file3.txt", strings.Join(got[2], "; "))
+
+	// When every consumer stops early,
+	// the Ranger can be used as soon as the sequences return.
+	seqs = tr.Tee(2)
+	for _, seq := range seqs {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for range seq {
+				return
+			}
+		}()
+	}
+	wg.Wait()
+	be.NilErr(t, tr.Err())
+	be.Equal(t, 4, len(slices.Collect(tr.FilePaths())))
+}
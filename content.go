@@ -0,0 +1,427 @@
+package walker
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"encoding/hex"
+	"hash"
+	"io"
+	"regexp"
+	"strings"
+	"unicode/utf16"
+	"unicode/utf8"
+)
+
+// MatchReadable returns a FilterFunc that matches entries
+// which the current process can open for reading.
+// It checks by opening and immediately closing each entry,
+// and failures are not passed to the ErrorPolicy,
+// so it quietly filters out unreadable files.
+// Entries from a NewFunc Ranger cannot be opened and never match.
+func MatchReadable() FilterFunc {
+	return func(e Entry) bool {
+		f, err := e.Open()
+		if err != nil {
+			return false
+		}
+		f.Close()
+		return true
+	}
+}
+
+// utf8BOM is the UTF-8 encoding of the byte order mark.
+var utf8BOM = []byte("\uFEFF")
+
+// DecodeText tells the Ranger whether its text content filters,
+// MatchFrontMatter, MatchFirstLineRegexp, MatchContentContains, and MatchContent,
+// should decode files beginning with a UTF-16 byte order mark to UTF-8
+// before matching.
+// A leading UTF-8 byte order mark is always removed.
+// Files without a byte order mark are read as is.
+func (tr *Ranger) DecodeText(decode bool) {
+	tr.decodeText = decode
+}
+
+// openText opens e for the text content filters.
+// It removes a leading UTF-8 byte order mark
+// and, if DecodeText is set, decodes UTF-16 with a byte order mark.
+func (tr *Ranger) openText(e Entry) (io.ReadCloser, error) {
+	f, err := e.Open()
+	if err != nil {
+		return nil, err
+	}
+	br := bufio.NewReader(f)
+	bom, _ := br.Peek(len(utf8BOM))
+	var r io.Reader = br
+	switch {
+	case bytes.HasPrefix(bom, utf8BOM):
+		_, _ = br.Discard(len(utf8BOM))
+	case tr.decodeText && bytes.HasPrefix(bom, []byte{0xFF, 0xFE}):
+		_, _ = br.Discard(2)
+		r = &utf16Reader{r: br, order: binary.LittleEndian}
+	case tr.decodeText && bytes.HasPrefix(bom, []byte{0xFE, 0xFF}):
+		_, _ = br.Discard(2)
+		r = &utf16Reader{r: br, order: binary.BigEndian}
+	}
+	return struct {
+		io.Reader
+		io.Closer
+	}{r, f}, nil
+}
+
+// utf16Reader decodes UTF-16 from r into UTF-8.
+// Invalid surrogates are replaced with utf8.RuneError
+// and a trailing odd byte is dropped.
+type utf16Reader struct {
+	r     io.Reader
+	order binary.ByteOrder
+	enc   [utf8.UTFMax]byte
+	buf   []byte
+}
+
+func (u *utf16Reader) Read(p []byte) (int, error) {
+	n := 0
+	for n < len(p) {
+		if len(u.buf) == 0 {
+			r, err := u.readRune()
+			if err != nil {
+				if n > 0 {
+					return n, nil
+				}
+				return 0, err
+			}
+			u.buf = u.enc[:utf8.EncodeRune(u.enc[:], r)]
+		}
+		c := copy(p[n:], u.buf)
+		n += c
+		u.buf = u.buf[c:]
+	}
+	return n, nil
+}
+
+// readRune decodes the next rune, joining surrogate pairs.
+func (u *utf16Reader) readRune() (rune, error) {
+	r1, err := u.readUnit()
+	if err != nil {
+		return 0, err
+	}
+	if !utf16.IsSurrogate(r1) {
+		return r1, nil
+	}
+	r2, err := u.readUnit()
+	if err != nil {
+		return utf8.RuneError, nil
+	}
+	return utf16.DecodeRune(r1, r2), nil
+}
+
+// readUnit reads the next UTF-16 code unit.
+func (u *utf16Reader) readUnit() (rune, error) {
+	var b [2]byte
+	if _, err := io.ReadFull(u.r, b[:]); err != nil {
+		if err == io.ErrUnexpectedEOF {
+			err = io.EOF
+		}
+		return 0, err
+	}
+	return rune(u.order.Uint16(b[:])), nil
+}
+
+// MatchFrontMatter returns a FilterFunc that matches files
+// beginning with a front matter block delimited by "---" lines
+// that contains a simple "key: value" line with the given key and value.
+// Surrounding whitespace and quotes around the value are ignored.
+// The file is read as text; see DecodeText.
+// Directories, files without front matter, and unreadable files do not match.
+func (tr *Ranger) MatchFrontMatter(key, value string) FilterFunc {
+	return func(e Entry) bool {
+		if e.IsDir() {
+			return false
+		}
+		f, err := tr.openText(e)
+		if err != nil {
+			return false
+		}
+		defer f.Close()
+		s := bufio.NewScanner(f)
+		if !s.Scan() || strings.TrimSpace(s.Text()) != "---" {
+			return false
+		}
+		for s.Scan() {
+			line := strings.TrimSpace(s.Text())
+			if line == "---" {
+				return false
+			}
+			k, v, ok := strings.Cut(line, ":")
+			if !ok || strings.TrimSpace(k) != key {
+				continue
+			}
+			v = strings.TrimSpace(v)
+			if len(v) >= 2 && (v[0] == '"' || v[0] == '\'') && v[len(v)-1] == v[0] {
+				v = v[1 : len(v)-1]
+			}
+			return v == value
+		}
+		return false
+	}
+}
+
+// MatchHashIn returns a FilterFunc that matches files
+// whose hex encoded hash is a key in set with a true value.
+// Files are hashed with a new hash.Hash from h, such as sha256.New.
+// Results are cached by path, so each file is read at most once.
+// Directories and unreadable files do not match.
+func MatchHashIn(set map[string]bool, h func() hash.Hash) FilterFunc {
+	cache := make(map[string]bool)
+	return func(e Entry) bool {
+		if e.IsDir() {
+			return false
+		}
+		if matched, ok := cache[e.Path]; ok {
+			return matched
+		}
+		sum, err := hashFile(e, h())
+		matched := err == nil && set[sum]
+		cache[e.Path] = matched
+		return matched
+	}
+}
+
+// hashFile returns the hex encoded hash of the contents of e.
+func hashFile(e Entry, h hash.Hash) (string, error) {
+	f, err := e.Open()
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+	if _, err = io.Copy(h, f); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+// maxFirstLine is the most bytes MatchFirstLineRegexp reads from a file.
+const maxFirstLine = 64 * 1024
+
+// MatchFirstLineRegexp returns a FilterFunc that matches files
+// whose first line matches re, such as a shebang line.
+// Only the first line is read, up to a limit of 64KB,
+// and its line ending is removed before matching.
+// The file is read as text; see DecodeText.
+// Directories and unreadable files do not match.
+func (tr *Ranger) MatchFirstLineRegexp(re *regexp.Regexp) FilterFunc {
+	return func(e Entry) bool {
+		if e.IsDir() {
+			return false
+		}
+		f, err := tr.openText(e)
+		if err != nil {
+			return false
+		}
+		defer f.Close()
+		line, err := bufio.NewReaderSize(f, maxFirstLine).ReadSlice('\n')
+		if err != nil && err != io.EOF && err != bufio.ErrBufferFull {
+			return false
+		}
+		line = bytes.TrimSuffix(line, []byte("\n"))
+		line = bytes.TrimSuffix(line, []byte("\r"))
+		return re.Match(line)
+	}
+}
+
+// contentBufSize is the size of the reads used by MatchContentContains.
+const contentBufSize = 32 * 1024
+
+// MatchContentContains returns a FilterFunc that matches files
+// whose contents contain substr.
+// Files are read in chunks and scanning stops at the first match,
+// so large files are never loaded into memory in full.
+// An empty substr matches every file.
+// The file is read as text; see DecodeText.
+// Directories and unreadable files do not match.
+func (tr *Ranger) MatchContentContains(substr string) FilterFunc {
+	needle := []byte(substr)
+	return func(e Entry) bool {
+		if e.IsDir() {
+			return false
+		}
+		f, err := tr.openText(e)
+		if err != nil {
+			return false
+		}
+		defer f.Close()
+		found, err := containsReader(tr.limitContent(f), needle)
+		return err == nil && found
+	}
+}
+
+// ContentScanLimit tells the Ranger to read at most n bytes of each file
+// in MatchContent and MatchContentContains,
+// so content past the limit is never matched
+// and scanning huge files stays cheap.
+// Pass n less than 1 to read whole files, which is the default.
+func (tr *Ranger) ContentScanLimit(n int64) {
+	tr.contentLimit = n
+}
+
+// limitContent limits r to the Ranger's ContentScanLimit, if any.
+func (tr *Ranger) limitContent(r io.Reader) io.Reader {
+	if tr.contentLimit < 1 {
+		return r
+	}
+	return io.LimitReader(r, tr.contentLimit)
+}
+
+// MatchContent returns a FilterFunc that matches files
+// whose contents match re.
+// Files are read incrementally and scanning stops at the first match,
+// so large files are never loaded into memory in full,
+// but a pattern which can only fail at the end of the input,
+// such as one ending in $, reads the whole file.
+// Files with a NUL byte in the first 8KB are considered binary and do not match.
+// The file is read as text; see DecodeText and ContentScanLimit.
+// Directories and unreadable files do not match,
+// and an error partway through a file is treated as the end of the file.
+func (tr *Ranger) MatchContent(re *regexp.Regexp) FilterFunc {
+	return func(e Entry) bool {
+		if e.IsDir() {
+			return false
+		}
+		f, err := tr.openText(e)
+		if err != nil {
+			return false
+		}
+		defer f.Close()
+		br := bufio.NewReaderSize(tr.limitContent(f), contentBufSize)
+		sample, err := br.Peek(maxTextSample)
+		if err != nil && err != io.EOF {
+			return false
+		}
+		if bytes.IndexByte(sample, 0) != -1 {
+			return false
+		}
+		return re.MatchReader(br)
+	}
+}
+
+// MatchContentMust is like MatchContent,
+// but it compiles expr with regexp.MustCompile.
+func (tr *Ranger) MatchContentMust(expr string) FilterFunc {
+	return tr.MatchContent(regexp.MustCompile(expr))
+}
+
+// containsReader reports whether r contains needle.
+// The end of each chunk is kept for the next read,
+// so needles that straddle a chunk boundary are found.
+func containsReader(r io.Reader, needle []byte) (bool, error) {
+	if len(needle) == 0 {
+		return true, nil
+	}
+	buf := make([]byte, contentBufSize+len(needle)-1)
+	keep := 0
+	for {
+		n, err := r.Read(buf[keep:])
+		data := buf[:keep+n]
+		if bytes.Contains(data, needle) {
+			return true, nil
+		}
+		keep = min(len(needle)-1, len(data))
+		copy(buf, data[len(data)-keep:])
+		if err == io.EOF {
+			return false, nil
+		}
+		if err != nil {
+			return false, err
+		}
+	}
+}
+
+// maxTextSample is the most bytes MatchNonUTF8Content reads from a file.
+const maxTextSample = 8 * 1024
+
+// MatchNonUTF8Content returns a FilterFunc that matches text files
+// whose contents are not valid UTF-8, such as Latin-1 encoded files.
+// Only the first 8KB of each file is checked,
+// ignoring a multibyte character cut off at the end of the sample.
+// Files containing a NUL byte in the sample are considered binary and do not match,
+// which also excludes UTF-16 text.
+// Directories, empty files, and unreadable files do not match.
+func MatchNonUTF8Content() FilterFunc {
+	return func(e Entry) bool {
+		if e.IsDir() {
+			return false
+		}
+		f, err := e.Open()
+		if err != nil {
+			return false
+		}
+		defer f.Close()
+		buf := make([]byte, maxTextSample)
+		n, err := io.ReadFull(f, buf)
+		if err != nil && err != io.EOF && err != io.ErrUnexpectedEOF {
+			return false
+		}
+		sample := buf[:n]
+		if bytes.IndexByte(sample, 0) != -1 {
+			return false
+		}
+		if n == len(buf) {
+			sample = trimPartialRune(sample)
+		}
+		return !utf8.Valid(sample)
+	}
+}
+
+// trimPartialRune removes an incomplete UTF-8 sequence from the end of b.
+func trimPartialRune(b []byte) []byte {
+	for i := 1; i < utf8.UTFMax && i <= len(b); i++ {
+		c := b[len(b)-i]
+		if utf8.RuneStart(c) {
+			if !utf8.FullRune(b[len(b)-i:]) {
+				return b[:len(b)-i]
+			}
+			break
+		}
+	}
+	return b
+}
+
+// MatchHasSibling returns a FilterFunc that matches files
+// with a sibling in the same directory named sibling(Entry.Name()),
+// such as a C file with a matching header:
+//
+//	tr.MatchHasSibling(func(name string) string {
+//		return strings.TrimSuffix(name, ".c") + ".h"
+//	})
+//
+// A file is not its own sibling.
+// Each directory is listed at most once and the listing is cached
+// for the life of the FilterFunc,
+// so changes to the tree after the first check are not seen.
+// Directories and files in unreadable directories do not match.
+func (tr *Ranger) MatchHasSibling(sibling func(name string) string) FilterFunc {
+	cache := make(map[string]map[string]bool)
+	return func(e Entry) bool {
+		if e.IsDir() {
+			return false
+		}
+		name := sibling(e.Name())
+		if name == e.Name() {
+			return false
+		}
+		dir := e.Dir()
+		names, ok := cache[dir]
+		if !ok {
+			entries, err := tr.readDirNamed(dir)
+			if err == nil {
+				names = make(map[string]bool, len(entries))
+				for _, d := range entries {
+					names[d.Name()] = true
+				}
+			}
+			cache[dir] = names
+		}
+		return names[name]
+	}
+}
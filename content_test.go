@@ -0,0 +1,280 @@
+package walker_test
+
+import (
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"io/fs"
+	"regexp"
+	"slices"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"testing/fstest"
+	"unicode/utf16"
+
+	"github.com/carlmjohnson/be"
+	"github.com/earthboundkid/walker"
+)
+
+func TestRanger_MatchFrontMatter(t *testing.T) {
+	testFS := fstest.MapFS{
+		"draft.md":     {Data: []byte("---\ntitle: Draft\ndraft: true\n---\nbody\n")},
+		"published.md": {Data: []byte("---\ntitle: Published\ndraft: false\n---\nbody\n")},
+		"quoted.md":    {Data: []byte("---\ndraft: \"false\"\n---\n")},
+		"nokey.md":     {Data: []byte("---\ntitle: No key\n---\ndraft: false\n")},
+		"plain.md":     {Data: []byte("draft: false\n")},
+		"empty.md":     {},
+	}
+	tr := walker.New(testFS, ".", walker.OnErrorHalt)
+	tr.Include(tr.MatchFrontMatter("draft", "false"))
+	paths := slices.Collect(tr.FilePaths())
+	be.NilErr(t, tr.Err())
+	be.Equal(t, "published.md; quoted.md", strings.Join(paths, "; "))
+}
+
+func TestMatchHashIn(t *testing.T) {
+	testFS := fstest.MapFS{
+		"a.txt":          {Data: []byte("a")},
+		"dir1/file3.txt": {Data: []byte("b")},
+		"dir1/file4.txt": {Data: []byte("c")},
+	}
+	sum := func(s string) string {
+		h := sha256.Sum256([]byte(s))
+		return hex.EncodeToString(h[:])
+	}
+	manifest := map[string]bool{
+		sum("a"): true,
+		sum("c"): true,
+	}
+	tr := walker.New(testFS, ".", walker.OnErrorHalt)
+	match := walker.MatchHashIn(manifest, sha256.New)
+	tr.Include(match)
+	paths := slices.Collect(tr.FilePaths())
+	be.NilErr(t, tr.Err())
+	be.Equal(t, "a.txt; dir1/file4.txt", strings.Join(paths, "; "))
+
+	tr.Include(walker.Not(match))
+	paths = slices.Collect(tr.FilePaths())
+	be.Equal(t, "dir1/file3.txt", strings.Join(paths, "; "))
+}
+
+func TestRanger_MatchFirstLineRegexp(t *testing.T) {
+	testFS := fstest.MapFS{
+		"bash":      {Data: []byte("#!/bin/bash\necho hi\n")},
+		"crlf":      {Data: []byte("#!/bin/bash\r\necho hi\r\n")},
+		"oneline":   {Data: []byte("#!/bin/bash")},
+		"python.py": {Data: []byte("#!/usr/bin/env python3\n")},
+		"later":     {Data: []byte("echo hi\n#!/bin/bash\n")},
+		"empty":     {},
+	}
+	tr := walker.New(testFS, ".", walker.OnErrorHalt)
+	tr.Include(tr.MatchFirstLineRegexp(regexp.MustCompile(`^#!.*/bash$`)))
+	paths := slices.Collect(tr.FilePaths())
+	be.NilErr(t, tr.Err())
+	be.Equal(t, "bash; crlf; oneline", strings.Join(paths, "; "))
+}
+
+func TestRanger_MatchContentContains(t *testing.T) {
+	const bufSize = 32 * 1024 // matches the read size of MatchContentContains
+	needle := "NEEDLE"
+	testFS := fstest.MapFS{
+		"empty.txt": {},
+		"small.txt": {Data: []byte("hay NEEDLE hay")},
+		"none.txt":  {Data: []byte(strings.Repeat("x", 3*bufSize))},
+		"partial.txt": {Data: []byte(strings.Repeat("x", bufSize-3) + "NEE" +
+			"x" + "DLE")},
+		"dir/sub.txt": {Data: []byte(needle)},
+	}
+	for _, offset := range []int{1, 3, 5, 6} {
+		name := fmt.Sprintf("straddle%d.txt", offset)
+		data := strings.Repeat("x", bufSize-offset) + needle + strings.Repeat("x", 100)
+		testFS[name] = &fstest.MapFile{Data: []byte(data)}
+	}
+	tr := walker.New(testFS, ".", walker.OnErrorHalt)
+	tr.Include(tr.MatchContentContains(needle))
+	paths := slices.Collect(tr.FilePaths())
+	be.NilErr(t, tr.Err())
+	be.Equal(t, "dir/sub.txt; small.txt; straddle1.txt; straddle3.txt; straddle5.txt; straddle6.txt",
+		strings.Join(paths, "; "))
+
+	tr.Include(tr.MatchContentContains(""))
+	be.Equal(t, 9, len(slices.Collect(tr.FilePaths())))
+}
+
+func TestRanger_MatchContent(t *testing.T) {
+	testFS := fstest.MapFS{
+		"hit.go":     {Data: []byte("package main\n\n// TODO: fix\nfunc main() {}\n")},
+		"miss.go":    {Data: []byte("package main\n\n// Done.\n")},
+		"binary.dat": {Data: []byte("\x00\x01TODO: binary")},
+		"late.txt":   {Data: []byte(strings.Repeat("x", 100) + "\nTODO: late\n")},
+		"dir/TODO":   {Data: []byte("nothing here")},
+	}
+	tr := walker.New(testFS, ".", walker.OnErrorHalt)
+	tr.Include(tr.MatchContentMust(`(?m)^// TODO|TODO: late`))
+	paths := slices.Collect(tr.Paths())
+	be.NilErr(t, tr.Err())
+	be.Equal(t, "hit.go; late.txt", strings.Join(paths, "; "))
+
+	// Content past the limit is not scanned.
+	tr.ContentScanLimit(50)
+	paths = slices.Collect(tr.Paths())
+	be.NilErr(t, tr.Err())
+	be.Equal(t, "hit.go", strings.Join(paths, "; "))
+
+	tr.Include(tr.MatchContentContains("TODO: late"))
+	be.Equal(t, 0, len(slices.Collect(tr.Paths())))
+	tr.ContentScanLimit(0)
+	be.Equal(t, "late.txt", strings.Join(slices.Collect(tr.Paths()), "; "))
+}
+
+func TestRanger_DecodeText(t *testing.T) {
+	encodeUTF16 := func(order binary.AppendByteOrder, s string) []byte {
+		data := order.AppendUint16(nil, 0xFEFF)
+		for _, u := range utf16.Encode([]rune(s)) {
+			data = order.AppendUint16(data, u)
+		}
+		return data
+	}
+	const text = "#!/bin/bash\necho \U0001F600 NEEDLE\n"
+	testFS := fstest.MapFS{
+		"plain":     {Data: []byte(text)},
+		"utf8bom":   {Data: []byte("\uFEFF" + text)},
+		"utf16le":   {Data: encodeUTF16(binary.LittleEndian, text)},
+		"utf16be":   {Data: encodeUTF16(binary.BigEndian, text)},
+		"truncated": {Data: encodeUTF16(binary.LittleEndian, text)[:5]},
+	}
+	tr := walker.New(testFS, ".", walker.OnErrorHalt)
+	tr.Include(tr.MatchFirstLineRegexp(regexp.MustCompile(`^#!.*/bash$`)))
+	paths := slices.Collect(tr.FilePaths())
+	be.NilErr(t, tr.Err())
+	be.Equal(t, "plain; utf8bom", strings.Join(paths, "; "))
+
+	tr.DecodeText(true)
+	paths = slices.Collect(tr.FilePaths())
+	be.NilErr(t, tr.Err())
+	be.Equal(t, "plain; utf16be; utf16le; utf8bom", strings.Join(paths, "; "))
+
+	tr.Include(tr.MatchContentContains("\U0001F600 NEEDLE"))
+	paths = slices.Collect(tr.FilePaths())
+	be.Equal(t, "plain; utf16be; utf16le; utf8bom", strings.Join(paths, "; "))
+
+	tr.DecodeText(false)
+	paths = slices.Collect(tr.FilePaths())
+	be.Equal(t, "plain; utf8bom", strings.Join(paths, "; "))
+}
+
+func TestMatchNonUTF8Content(t *testing.T) {
+	const sampleSize = 8 * 1024 // matches the sample size of MatchNonUTF8Content
+	testFS := fstest.MapFS{
+		"utf8.txt":   {Data: []byte("caf\u00e9 \u65e5\u672c\n")},
+		"latin1.txt": {Data: []byte("caf\xe9\n")},
+		"binary.bin": {Data: []byte("caf\xe9\x00\x01\x02")},
+		"empty.txt":  {},
+		// A multibyte character straddling the end of the sample is not an error.
+		"cut.txt": {Data: []byte(strings.Repeat("x", sampleSize-1) + "\u00e9")},
+		// Invalid bytes after the sample are not seen.
+		"late.txt": {Data: []byte(strings.Repeat("x", sampleSize) + "\xe9")},
+	}
+	tr := walker.New(testFS, ".", walker.OnErrorHalt)
+	tr.Include(walker.MatchNonUTF8Content())
+	paths := slices.Collect(tr.FilePaths())
+	be.NilErr(t, tr.Err())
+	be.Equal(t, "latin1.txt", strings.Join(paths, "; "))
+}
+
+func TestRanger_MatchHasSibling(t *testing.T) {
+	testFS := fstest.MapFS{
+		"foo.c":     {},
+		"foo.h":     {},
+		"bar.c":     {},
+		"lib/baz.c": {},
+		"lib/baz.h": {},
+		"lib/qux.c": {},
+		"qux.h":     {},
+	}
+	tr := walker.New(testFS, ".", walker.OnErrorHalt)
+	tr.Include(walker.And(
+		walker.MatchExtension(".c"),
+		tr.MatchHasSibling(func(name string) string {
+			return strings.TrimSuffix(name, ".c") + ".h"
+		}),
+	))
+	paths := slices.Collect(tr.FilePaths())
+	be.NilErr(t, tr.Err())
+	be.Equal(t, "foo.c; lib/baz.c", strings.Join(paths, "; "))
+
+	tr.Include(tr.MatchHasSibling(func(name string) string { return name }))
+	be.Equal(t, 0, len(slices.Collect(tr.FilePaths())))
+}
+
+// countingFS is an fs.FS which tracks the most files open at once.
+type countingFS struct {
+	fstest.MapFS
+	open, peak atomic.Int64
+}
+
+func (fsys *countingFS) Open(name string) (fs.File, error) {
+	f, err := fsys.MapFS.Open(name)
+	if err != nil {
+		return nil, err
+	}
+	n := fsys.open.Add(1)
+	for {
+		peak := fsys.peak.Load()
+		if n <= peak || fsys.peak.CompareAndSwap(peak, n) {
+			break
+		}
+	}
+	return countingFile{f, &fsys.open}, nil
+}
+
+type countingFile struct {
+	fs.File
+	open *atomic.Int64
+}
+
+func (f countingFile) Close() error {
+	f.open.Add(-1)
+	return f.File.Close()
+}
+
+func TestRanger_OpenFileLimit(t *testing.T) {
+	testFS := &countingFS{MapFS: fstest.MapFS{}}
+	for i := range 20 {
+		data := "hay"
+		if i%2 == 0 {
+			data = "NEEDLE"
+		}
+		name := fmt.Sprintf("dir%d/file%d.txt", i%4, i)
+		testFS.MapFS[name] = &fstest.MapFile{Data: []byte(data)}
+	}
+	tr := walker.New(testFS, ".", walker.OnErrorHalt)
+	tr.OpenFileLimit(2)
+	tr.Include(tr.MatchContentContains("NEEDLE"))
+
+	var wg sync.WaitGroup
+	counts := make([]int, 8)
+	for i := range counts {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			clone := tr.Clone()
+			for e := range clone.FileEntries() {
+				counts[i]++
+				_, err := e.WriteTo(io.Discard)
+				be.NilErr(t, err)
+			}
+			be.NilErr(t, clone.Err())
+		}()
+	}
+	wg.Wait()
+	for _, n := range counts {
+		be.Equal(t, 10, n)
+	}
+	be.True(t, testFS.peak.Load() <= 2)
+	be.Equal(t, int64(0), testFS.open.Load())
+}
@@ -0,0 +1,161 @@
+package walker
+
+import (
+	"errors"
+	"iter"
+)
+
+// isWithin reports whether e is dir or one of its descendants.
+func (e Entry) isWithin(dir Entry) bool {
+	return isPrefix(dir.relSegments(), e.relSegments())
+}
+
+// depthFirst turns off BreadthFirst until the returned function is called,
+// for walks which rely on a directory's contents following it.
+func (tr *Ranger) depthFirst() (restore func()) {
+	breadthFirst := tr.breadthFirst
+	tr.breadthFirst = false
+	return func() {
+		tr.breadthFirst = breadthFirst
+	}
+}
+
+// ForEachDir calls fn once for each directory that passes the directory filters,
+// passing it the matching files directly inside that directory.
+// Directories are passed to fn when the walk leaves them,
+// so subdirectories are handled before their parents.
+// If fn returns an error, the walk stops
+// and ForEachDir returns that error wrapped in a *CallbackError.
+// Otherwise, it returns the Ranger's Err(), if any, wrapped in a *WalkError.
+// The tree is always walked depth first, even if BreadthFirst is set.
+func (tr *Ranger) ForEachDir(fn func(dir Entry, files []Entry) error) error {
+	defer tr.depthFirst()()
+	type frame struct {
+		dir   Entry
+		files []Entry
+	}
+	var stack []frame
+	leave := func() error {
+		top := stack[len(stack)-1]
+		stack = stack[:len(stack)-1]
+		if err := fn(top.dir, top.files); err != nil {
+			return &CallbackError{top.dir.Path, err}
+		}
+		return nil
+	}
+	for e, included := range tr.visit {
+		for len(stack) > 0 && !e.isWithin(stack[len(stack)-1].dir) {
+			if err := leave(); err != nil {
+				return err
+			}
+		}
+		if e.IsDir() {
+			stack = append(stack, frame{dir: e})
+			continue
+		}
+		if included && len(stack) > 0 && stack[len(stack)-1].dir.Path == e.Dir() {
+			stack[len(stack)-1].files = append(stack[len(stack)-1].files, e)
+		}
+	}
+	for len(stack) > 0 {
+		if err := leave(); err != nil {
+			return err
+		}
+	}
+	return tr.walkError()
+}
+
+// EachPostOrder calls fn for each matching file and directory,
+// visiting the contents of a directory before the directory itself,
+// so files come before their directories and directories before their parents,
+// as when removing a tree.
+// Files are passed to fn as they are walked,
+// and directories are held until the walk leaves them.
+// If fn returns an error, the walk stops
+// and EachPostOrder returns that error wrapped in a *CallbackError.
+// Otherwise, it returns the Ranger's Err(), if any, wrapped in a *WalkError.
+// The tree is always walked depth first, even if BreadthFirst is set.
+func (tr *Ranger) EachPostOrder(fn func(Entry) error) error {
+	defer tr.depthFirst()()
+	var stack []Entry
+	leave := func() error {
+		top := stack[len(stack)-1]
+		stack = stack[:len(stack)-1]
+		if err := fn(top); err != nil {
+			return &CallbackError{top.Path, err}
+		}
+		return nil
+	}
+	for e := range tr.Entries() {
+		for len(stack) > 0 && !e.isWithin(stack[len(stack)-1]) {
+			if err := leave(); err != nil {
+				return err
+			}
+		}
+		if e.IsDir() {
+			stack = append(stack, e)
+			continue
+		}
+		if err := fn(e); err != nil {
+			return &CallbackError{e.Path, err}
+		}
+	}
+	for len(stack) > 0 {
+		if err := leave(); err != nil {
+			return err
+		}
+	}
+	return tr.walkError()
+}
+
+// SkipSubtree can be returned by the function passed to ForEachDirFunc
+// to skip the contents of the directory it was called with.
+var SkipSubtree = errors.New("walker: skip subtree")
+
+// ForEachDirFunc calls fn for each directory that passes the directory filters,
+// in the order they are walked, so parents are handled before their subdirectories.
+// If fn returns SkipSubtree, the directory's contents are not walked.
+// If fn returns any other error, the walk stops
+// and ForEachDirFunc returns that error wrapped in a *CallbackError.
+// Otherwise, it returns the Ranger's Err(), if any, wrapped in a *WalkError.
+func (tr *Ranger) ForEachDirFunc(fn func(dir Entry) error) error {
+	for e := range tr.visit {
+		if !e.IsDir() {
+			continue
+		}
+		err := fn(e)
+		if errors.Is(err, SkipSubtree) {
+			tr.SkipDir()
+			continue
+		}
+		if err != nil {
+			return &CallbackError{e.Path, err}
+		}
+	}
+	return tr.walkError()
+}
+
+// LeafDirs walks the tree and returns a sequence of the directories
+// that pass the directory filters but contain no subdirectories that do,
+// in the order they were walked, along with the Ranger's Err().
+// With BreadthFirst, shallower leaves come before deeper ones.
+func (tr *Ranger) LeafDirs() (iter.Seq[Entry], error) {
+	var dirs []Entry
+	hasChild := make(map[string]bool)
+	for e := range tr.visit {
+		if !e.IsDir() {
+			continue
+		}
+		dirs = append(dirs, e)
+		if e.RelRoot() != "." {
+			hasChild[e.parent()] = true
+		}
+	}
+	return func(yield func(Entry) bool) {
+		for _, dir := range dirs {
+			if !hasChild[dir.Path] && !yield(dir) {
+				return
+			}
+		}
+	}, tr.Err()
+}
@@ -0,0 +1,189 @@
+package walker_test
+
+import (
+	"errors"
+	"io/fs"
+	"slices"
+	"strings"
+	"testing"
+	"testing/fstest"
+
+	"github.com/carlmjohnson/be"
+	"github.com/earthboundkid/walker"
+)
+
+func TestRanger_ForEachDir(t *testing.T) {
+	testFS := fstest.MapFS{
+		"a.txt":                &fstest.MapFile{},
+		"dir1/file3.txt":       &fstest.MapFile{},
+		"dir1/file4.log":       &fstest.MapFile{},
+		"dir2/file5.txt":       &fstest.MapFile{},
+		"dir2/subdir/file6.go": &fstest.MapFile{},
+		"file1.txt":            &fstest.MapFile{},
+		"file2.log":            &fstest.MapFile{},
+	}
+	tr := walker.New(testFS, ".", walker.OnErrorHalt)
+	tr.Include(walker.MatchExtension(".txt", ".go"))
+	var got []string
+	err := tr.ForEachDir(func(dir walker.Entry, files []walker.Entry) error {
+		var names []string
+		for _, f := range files {
+			names = append(names, f.Name())
+		}
+		got = append(got, dir.Path+": "+strings.Join(names, " "))
+		return nil
+	})
+	be.NilErr(t, err)
+	be.AllEqual(t, []string{
+		"dir1: file3.txt",
+		"dir2/subdir: file6.go",
+		"dir2: file5.txt",
+		".: a.txt file1.txt",
+	}, got)
+
+	tr.BreadthFirst(true)
+	got = nil
+	err = tr.ForEachDir(func(dir walker.Entry, files []walker.Entry) error {
+		var names []string
+		for _, f := range files {
+			names = append(names, f.Name())
+		}
+		got = append(got, dir.Path+": "+strings.Join(names, " "))
+		return nil
+	})
+	be.NilErr(t, err)
+	be.AllEqual(t, []string{
+		"dir1: file3.txt",
+		"dir2/subdir: file6.go",
+		"dir2: file5.txt",
+		".: a.txt file1.txt",
+	}, got)
+	// The Ranger still walks breadth first afterwards.
+	be.Equal(t, "a.txt; file1.txt; dir1/file3.txt; dir2/file5.txt; dir2/subdir/file6.go",
+		strings.Join(slices.Collect(tr.FilePaths()), "; "))
+
+	errStop := errors.New("stop")
+	got = nil
+	err = tr.ForEachDir(func(dir walker.Entry, files []walker.Entry) error {
+		got = append(got, dir.Path)
+		return errStop
+	})
+	be.True(t, errors.Is(err, errStop))
+	be.AllEqual(t, []string{"dir1"}, got)
+	var cbErr *walker.CallbackError
+	be.True(t, errors.As(err, &cbErr))
+	be.Equal(t, "dir1", cbErr.Path)
+}
+
+func TestRanger_EachPostOrder(t *testing.T) {
+	testFS := fstest.MapFS{
+		"a.txt":                &fstest.MapFile{},
+		"dir1/file3.txt":       &fstest.MapFile{},
+		"dir2/file5.txt":       &fstest.MapFile{},
+		"dir2/subdir/file6.go": &fstest.MapFile{},
+		"dir2/z.txt":           &fstest.MapFile{},
+		"file1.txt":            &fstest.MapFile{},
+	}
+	tr := walker.New(testFS, ".", walker.OnErrorHalt)
+	var got []string
+	err := tr.EachPostOrder(func(e walker.Entry) error {
+		got = append(got, e.Path)
+		return nil
+	})
+	be.NilErr(t, err)
+	be.Equal(t, "a.txt dir1/file3.txt dir1 dir2/file5.txt dir2/subdir/file6.go dir2/subdir "+
+		"dir2/z.txt dir2 file1.txt .", strings.Join(got, " "))
+
+	tr.BreadthFirst(true)
+	got = nil
+	err = tr.EachPostOrder(func(e walker.Entry) error {
+		got = append(got, e.Path)
+		return nil
+	})
+	be.NilErr(t, err)
+	be.Equal(t, "a.txt dir1/file3.txt dir1 dir2/file5.txt dir2/subdir/file6.go dir2/subdir "+
+		"dir2/z.txt dir2 file1.txt .", strings.Join(got, " "))
+	tr.BreadthFirst(false)
+
+	errStop := errors.New("stop")
+	got = nil
+	err = tr.EachPostOrder(func(e walker.Entry) error {
+		got = append(got, e.Path)
+		if e.Path == "dir2/subdir" {
+			return errStop
+		}
+		return nil
+	})
+	be.True(t, errors.Is(err, errStop))
+	be.Equal(t, "a.txt dir1/file3.txt dir1 dir2/file5.txt dir2/subdir/file6.go dir2/subdir",
+		strings.Join(got, " "))
+	var cbErr *walker.CallbackError
+	be.True(t, errors.As(err, &cbErr))
+	be.Equal(t, "dir2/subdir", cbErr.Path)
+}
+
+func TestRanger_ForEachDirFunc(t *testing.T) {
+	testFS := fstest.MapFS{
+		"a.txt":                    &fstest.MapFile{},
+		"dir1/file3.txt":           &fstest.MapFile{},
+		"dir2/file5.txt":           &fstest.MapFile{},
+		"dir2/subdir/file6.go":     &fstest.MapFile{},
+		"dir2/subdir/deeper/x.txt": &fstest.MapFile{},
+		"dir3/file7.txt":           &fstest.MapFile{},
+		"vendor/pkg/file.go":       &fstest.MapFile{},
+	}
+	tr := walker.New(testFS, ".", walker.OnErrorHalt)
+	tr.Include(walker.MatchExtension(".txt"))
+	tr.ExcludeDir(walker.MatchGlobName("vendor"))
+	var got []string
+	err := tr.ForEachDirFunc(func(dir walker.Entry) error {
+		got = append(got, dir.Path)
+		if dir.Path == "dir2/subdir" {
+			return walker.SkipSubtree
+		}
+		return nil
+	})
+	be.NilErr(t, err)
+	be.Equal(t, ". dir1 dir2 dir2/subdir dir3", strings.Join(got, " "))
+
+	errStop := errors.New("stop")
+	got = nil
+	err = tr.ForEachDirFunc(func(dir walker.Entry) error {
+		got = append(got, dir.Path)
+		if dir.Path == "dir2" {
+			return errStop
+		}
+		return nil
+	})
+	be.True(t, errors.Is(err, errStop))
+	be.Equal(t, ". dir1 dir2", strings.Join(got, " "))
+}
+
+func TestRanger_LeafDirs(t *testing.T) {
+	testFS := fstest.MapFS{
+		"a.txt":                &fstest.MapFile{},
+		"dir1/file3.txt":       &fstest.MapFile{},
+		"dir2/file5.txt":       &fstest.MapFile{},
+		"dir2/subdir/file6.go": &fstest.MapFile{},
+		"dir3/skip/file7.txt":  &fstest.MapFile{},
+		"empty":                &fstest.MapFile{Mode: fs.ModeDir},
+	}
+	tr := walker.New(testFS, ".", walker.OnErrorHalt)
+	tr.ExcludeDir(walker.MatchGlobName("skip"))
+	seq, err := tr.LeafDirs()
+	be.NilErr(t, err)
+	var got []string
+	for dir := range seq {
+		got = append(got, dir.Path)
+	}
+	be.Equal(t, "dir1 dir2/subdir dir3 empty", strings.Join(got, " "))
+
+	tr.BreadthFirst(true)
+	seq, err = tr.LeafDirs()
+	be.NilErr(t, err)
+	got = nil
+	for dir := range seq {
+		got = append(got, dir.Path)
+	}
+	be.Equal(t, "dir1 dir3 empty dir2/subdir", strings.Join(got, " "))
+}
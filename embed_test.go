@@ -0,0 +1,58 @@
+package walker_test
+
+import (
+	"embed"
+	"fmt"
+	"os"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/carlmjohnson/be"
+	"github.com/earthboundkid/walker"
+)
+
+//go:embed testdata/assets
+var assets embed.FS
+
+func TestRanger_embed(t *testing.T) {
+	tr := walker.New(assets, "testdata/assets", walker.OnErrorHalt)
+	be.NilErr(t, tr.Validate())
+	var rels []string
+	for e := range tr.FileEntries() {
+		rels = append(rels, e.RelRoot())
+		var buf strings.Builder
+		_, err := e.WriteTo(&buf)
+		be.NilErr(t, err)
+		want, err := os.ReadFile(e.Path)
+		be.NilErr(t, err)
+		be.Equal(t, string(want), buf.String())
+	}
+	be.NilErr(t, tr.Err())
+	be.Equal(t, "css/site.css; hello.txt", strings.Join(rels, "; "))
+
+	tr = walker.New(assets, ".", walker.OnErrorHalt)
+	paths := slices.Collect(tr.FilePaths())
+	be.Equal(t, "testdata/assets/css/site.css; testdata/assets/hello.txt", strings.Join(paths, "; "))
+}
+
+func ExampleRanger_embed() {
+	// assets is an embed.FS containing testdata/assets
+	tr := walker.New(assets, "testdata/assets", walker.OnErrorHalt)
+	tr.Include(walker.MatchExtension(".txt"))
+
+	for e := range tr.FileEntries() {
+		fmt.Println("-", e.RelRoot())
+		// Entries can be read from the embed.FS
+		if _, err := e.WriteTo(os.Stdout); err != nil {
+			panic(err)
+		}
+	}
+	// Do a final error check
+	if tr.HasError() {
+		panic(tr.Err())
+	}
+	// Output:
+	// - hello.txt
+	// Hello, embed!
+}
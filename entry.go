@@ -1,17 +1,31 @@
 package walker
 
 import (
+	"cmp"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
 	"io/fs"
+	"os"
 	"path"
 	"path/filepath"
+	"strings"
+	"sync"
 )
 
 // Entry is a single path/fs.DirEntry pair yielded by a Ranger.
 // It knows whether to use package filepath or package path for its methods.
+// The DirEntry of an Entry yielded by a Ranger caches the result of Info,
+// so filters which read the size or modification time of an Entry
+// share a single stat.
 type Entry struct {
 	Path        string
 	DirEntry    fs.DirEntry
+	root        string
+	fsys        fs.FS
 	useFilepath bool
+	openSem     chan struct{}
 }
 
 // IsDir returns whether the DirEntry is a directory.
@@ -23,6 +37,43 @@ func (e Entry) IsDir() bool {
 	return e.DirEntry.IsDir()
 }
 
+// ModeType returns the type bits of DirEntry.Type().
+// If DirEntry is nil, it returns 0.
+// It and the type predicates below do not need to call Info().
+func (e Entry) ModeType() fs.FileMode {
+	if e.DirEntry == nil {
+		return 0
+	}
+	return e.DirEntry.Type().Type()
+}
+
+// IsRegular reports whether the Entry is a regular file.
+// If DirEntry is nil, it returns false.
+func (e Entry) IsRegular() bool {
+	return e.DirEntry != nil && e.ModeType().IsRegular()
+}
+
+// IsSymlink reports whether the Entry is a symbolic link.
+func (e Entry) IsSymlink() bool {
+	return e.ModeType()&fs.ModeSymlink != 0
+}
+
+// IsNamedPipe reports whether the Entry is a named pipe (FIFO).
+func (e Entry) IsNamedPipe() bool {
+	return e.ModeType()&fs.ModeNamedPipe != 0
+}
+
+// IsSocket reports whether the Entry is a Unix domain socket.
+func (e Entry) IsSocket() bool {
+	return e.ModeType()&fs.ModeSocket != 0
+}
+
+// IsDevice reports whether the Entry is a device file,
+// including character devices.
+func (e Entry) IsDevice() bool {
+	return e.ModeType()&fs.ModeDevice != 0
+}
+
 // Name returns DirEntry.Name().
 // If DirEntry is nil, it returns "".
 func (e Entry) Name() string {
@@ -46,6 +97,43 @@ func (e Entry) Dir() string {
 	return path.Dir(e.Path)
 }
 
+// parent returns the directory containing the Entry.
+// Unlike Dir, it returns the parent directory even if e is a directory.
+func (e Entry) parent() string {
+	if e.useFilepath {
+		return filepath.Dir(e.Path)
+	}
+	return path.Dir(e.Path)
+}
+
+// parentEntry returns a directory Entry for the directory containing e.
+// Its DirEntry only stats the directory if Info is called.
+func (e Entry) parentEntry() Entry {
+	dir := e
+	dir.Path = e.parent()
+	dir.DirEntry = parentDirEntry{dir}
+	return dir
+}
+
+// parentDirEntry is the fs.DirEntry of a directory Entry made by parentEntry.
+type parentDirEntry struct {
+	dir Entry
+}
+
+func (d parentDirEntry) Name() string      { return d.dir.Base() }
+func (d parentDirEntry) IsDir() bool       { return true }
+func (d parentDirEntry) Type() fs.FileMode { return fs.ModeDir }
+
+func (d parentDirEntry) Info() (fs.FileInfo, error) {
+	switch {
+	case d.dir.useFilepath:
+		return os.Stat(d.dir.Path)
+	case d.dir.fsys != nil:
+		return fs.Stat(d.dir.fsys, d.dir.Path)
+	}
+	return rootInfo(d.Name()), nil
+}
+
 // Base returns the last element of Path, typically the filename.
 // See [path.Base] and [filepath.Base].
 func (e Entry) Base() string {
@@ -74,3 +162,162 @@ func (e Entry) Split() (string, string) {
 	}
 	return path.Split(e.Path)
 }
+
+// slashPath returns Path with its separators converted to forward slashes.
+func (e Entry) slashPath() string {
+	if e.useFilepath {
+		return filepath.ToSlash(e.Path)
+	}
+	return e.Path
+}
+
+// Key returns the cleaned Path, which identifies an Entry within a walk,
+// for use as a map key or set member.
+// Entries should not be compared with == or used as map keys directly,
+// because DirEntry is an interface whose dynamic value
+// may not be comparable and differs between reads of the same directory.
+func (e Entry) Key() string {
+	if e.useFilepath {
+		return filepath.Clean(e.Path)
+	}
+	return path.Clean(e.Path)
+}
+
+// RelRoot returns Path relative to the root of the walk that produced the Entry.
+// The root itself is returned as ".".
+func (e Entry) RelRoot() string {
+	if e.useFilepath {
+		rel, err := filepath.Rel(e.root, e.Path)
+		if err != nil {
+			return e.Path
+		}
+		return rel
+	}
+	switch {
+	case e.Path == e.root:
+		return "."
+	case e.root == ".":
+		return e.Path
+	}
+	return strings.TrimPrefix(e.Path, e.root+"/")
+}
+
+// MarshalJSON implements json.Marshaler.
+// An Entry is encoded as an object with its path, name, and whether it is a directory.
+func (e Entry) MarshalJSON() ([]byte, error) {
+	return json.Marshal(struct {
+		Path  string `json:"path"`
+		Name  string `json:"name"`
+		IsDir bool   `json:"is_dir"`
+	}{e.Path, e.Name(), e.IsDir()})
+}
+
+// Info returns DirEntry.Info(), or an error if DirEntry is nil.
+// For an Entry yielded by a Ranger, the result is cached,
+// so calling Info repeatedly does not stat the file again.
+func (e Entry) Info() (fs.FileInfo, error) {
+	if e.DirEntry == nil {
+		return nil, &fs.PathError{Op: "stat", Path: e.Path, Err: fs.ErrInvalid}
+	}
+	return e.DirEntry.Info()
+}
+
+// Open opens the file at Path using the filesystem that produced the Entry:
+// the Ranger's fs.FS if it has one, or the OS filesystem otherwise.
+// Entries from a NewFunc Ranger cannot be opened
+// and return an error wrapping errors.ErrUnsupported.
+// If the Ranger has an OpenFileLimit,
+// Open waits for a slot, which is released when the file is closed.
+func (e Entry) Open() (fs.File, error) {
+	if e.openSem == nil {
+		return e.openPath(e.Path)
+	}
+	e.openSem <- struct{}{}
+	f, err := e.openPath(e.Path)
+	if err != nil {
+		<-e.openSem
+		return nil, err
+	}
+	return &limitedFile{File: f, sem: e.openSem}, nil
+}
+
+// limitedFile is a file holding a slot of an OpenFileLimit.
+type limitedFile struct {
+	fs.File
+	sem  chan struct{}
+	once sync.Once
+}
+
+func (f *limitedFile) Close() error {
+	err := f.File.Close()
+	f.once.Do(func() { <-f.sem })
+	return err
+}
+
+// openPath opens name using the filesystem that produced the Entry.
+func (e Entry) openPath(name string) (fs.File, error) {
+	switch {
+	case e.useFilepath:
+		return os.Open(name)
+	case e.fsys == nil:
+		return nil, &fs.PathError{Op: "open", Path: name, Err: errors.ErrUnsupported}
+	}
+	return e.fsys.Open(name)
+}
+
+// OpenChild opens the file called name inside the directory e
+// using the filesystem that produced the Entry.
+// It returns an error if e is not a directory.
+func (e Entry) OpenChild(name string) (fs.File, error) {
+	if !e.IsDir() {
+		return nil, fmt.Errorf("walker: cannot open child of non-directory %q", e.Path)
+	}
+	if e.useFilepath {
+		return e.openPath(filepath.Join(e.Path, name))
+	}
+	return e.openPath(path.Join(e.Path, name))
+}
+
+// WriteTo implements io.WriterTo by copying the contents of the file to w.
+// It returns an error if the Entry is a directory.
+func (e Entry) WriteTo(w io.Writer) (int64, error) {
+	if e.IsDir() {
+		return 0, fmt.Errorf("walker: cannot write directory %q", e.Path)
+	}
+	f, err := e.Open()
+	if err != nil {
+		return 0, err
+	}
+	defer f.Close()
+	return io.Copy(w, f)
+}
+
+// CompareByPath compares entries by Path,
+// for use with slices.SortFunc.
+func CompareByPath(a, b Entry) int {
+	return strings.Compare(a.Path, b.Path)
+}
+
+// CompareByName compares entries by Name(),
+// for use with slices.SortFunc.
+func CompareByName(a, b Entry) int {
+	return strings.Compare(a.Name(), b.Name())
+}
+
+// CompareBySize compares entries by the size from Info(),
+// for use with slices.SortFunc.
+// Entries whose info cannot be read are treated as having size zero.
+func CompareBySize(a, b Entry) int {
+	sizeA, _ := size(a)
+	sizeB, _ := size(b)
+	return cmp.Compare(sizeA, sizeB)
+}
+
+// CompareByModTime compares entries by the modification time from Info(),
+// for use with slices.SortFunc.
+// Entries whose info cannot be read are treated as having the zero time.
+func CompareByModTime(a, b Entry) int {
+	timeA, _ := modTime(a)
+	timeB, _ := modTime(b)
+	return timeA.Compare(timeB)
+}
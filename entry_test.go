@@ -0,0 +1,294 @@
+package walker_test
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"bytes"
+	"errors"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+	"testing/fstest"
+	"time"
+
+	"github.com/carlmjohnson/be"
+	"github.com/earthboundkid/walker"
+)
+
+func TestEntry_RelRoot(t *testing.T) {
+	testFS := fstest.MapFS{
+		"a.txt":                &fstest.MapFile{},
+		"dir2/file5.txt":       &fstest.MapFile{},
+		"dir2/subdir/file6.go": &fstest.MapFile{},
+	}
+	temp := t.TempDir()
+	be.NilErr(t, os.CopyFS(temp, testFS))
+
+	for _, tc := range []struct {
+		name string
+		tr   walker.Ranger
+		want string
+	}{
+		{"fs root", walker.New(testFS, ".", walker.OnErrorHalt),
+			". a.txt dir2 dir2/file5.txt dir2/subdir dir2/subdir/file6.go"},
+		{"fs subdir", walker.New(testFS, "dir2", walker.OnErrorHalt),
+			". file5.txt subdir subdir/file6.go"},
+		{"os root", walker.New(nil, temp, walker.OnErrorHalt),
+			". a.txt dir2 dir2/file5.txt dir2/subdir dir2/subdir/file6.go"},
+		{"os subdir", walker.New(nil, filepath.Join(temp, "dir2"), walker.OnErrorHalt),
+			". file5.txt subdir subdir/file6.go"},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			var paths []string
+			for e := range tc.tr.Entries() {
+				paths = append(paths, filepath.ToSlash(e.RelRoot()))
+			}
+			be.NilErr(t, tc.tr.Err())
+			be.Equal(t, tc.want, strings.Join(paths, " "))
+		})
+	}
+}
+
+func TestEntry_RelRoot_dot(t *testing.T) {
+	temp := t.TempDir()
+	be.NilErr(t, os.WriteFile(filepath.Join(temp, "a.txt"), nil, 0o644))
+	wd, err := os.Getwd()
+	be.NilErr(t, err)
+	be.NilErr(t, os.Chdir(temp))
+	t.Cleanup(func() { be.NilErr(t, os.Chdir(wd)) })
+
+	tr := walker.New(nil, ".", walker.OnErrorHalt)
+	var paths []string
+	for e := range tr.Entries() {
+		paths = append(paths, e.RelRoot())
+	}
+	be.NilErr(t, tr.Err())
+	be.Equal(t, ". a.txt", strings.Join(paths, " "))
+}
+
+func TestEntry_WriteTo(t *testing.T) {
+	testFS := fstest.MapFS{
+		"dir1/file3.txt": &fstest.MapFile{Data: []byte("hello, world\n")},
+	}
+	temp := t.TempDir()
+	be.NilErr(t, os.CopyFS(temp, testFS))
+
+	for _, tr := range []walker.Ranger{
+		walker.New(testFS, ".", walker.OnErrorHalt),
+		walker.New(nil, temp, walker.OnErrorHalt),
+	} {
+		for e := range tr.Entries() {
+			var buf bytes.Buffer
+			n, err := e.WriteTo(&buf)
+			if e.IsDir() {
+				be.Nonzero(t, err)
+				continue
+			}
+			be.NilErr(t, err)
+			be.Equal(t, 13, n)
+			be.Equal(t, "hello, world\n", buf.String())
+		}
+		be.NilErr(t, tr.Err())
+	}
+}
+
+func TestEntry_Open(t *testing.T) {
+	testFS := fstest.MapFS{
+		"dir1/file3.txt": &fstest.MapFile{Data: []byte("hello, world\n")},
+	}
+	temp := t.TempDir()
+	be.NilErr(t, os.CopyFS(temp, testFS))
+
+	for _, tr := range []walker.Ranger{
+		walker.New(testFS, ".", walker.OnErrorHalt),
+		walker.New(nil, temp, walker.OnErrorHalt),
+	} {
+		for e := range tr.FileEntries() {
+			f, err := e.Open()
+			be.NilErr(t, err)
+			data, err := io.ReadAll(f)
+			be.NilErr(t, err)
+			be.NilErr(t, f.Close())
+			be.Equal(t, "hello, world\n", string(data))
+
+			info, err := e.Info()
+			be.NilErr(t, err)
+			be.Equal(t, 13, info.Size())
+			be.Equal(t, "file3.txt", info.Name())
+		}
+		be.NilErr(t, tr.Err())
+	}
+
+	_, err := walker.Entry{Path: "x"}.Info()
+	be.True(t, errors.Is(err, fs.ErrInvalid))
+
+	tr := walker.NewFunc(func(string) ([]fs.DirEntry, error) {
+		return nil, nil
+	}, "root", walker.OnErrorHalt)
+	for e := range tr.Entries() {
+		_, err := e.Open()
+		be.True(t, errors.Is(err, errors.ErrUnsupported))
+	}
+}
+
+func TestEntry_TarHeader(t *testing.T) {
+	modTime := time.Date(2024, 6, 1, 12, 0, 0, 0, time.UTC)
+	testFS := fstest.MapFS{
+		"dir1":           &fstest.MapFile{Mode: fs.ModeDir | 0o755, ModTime: modTime},
+		"dir1/file3.txt": &fstest.MapFile{Data: []byte("hello"), Mode: 0o644, ModTime: modTime},
+	}
+	tr := walker.New(testFS, ".", walker.OnErrorHalt)
+	headers := map[string]*tar.Header{}
+	zipHeaders := map[string]*zip.FileHeader{}
+	for e := range tr.Entries() {
+		h, err := e.TarHeader()
+		be.NilErr(t, err)
+		headers[e.Path] = h
+		zh, err := e.ZipHeader()
+		be.NilErr(t, err)
+		zipHeaders[e.Path] = zh
+	}
+	be.NilErr(t, tr.Err())
+
+	h := headers["dir1/file3.txt"]
+	be.Equal(t, "dir1/file3.txt", h.Name)
+	be.Equal(t, tar.TypeReg, h.Typeflag)
+	be.Equal(t, 5, h.Size)
+	be.Equal(t, 0o644, h.Mode)
+	be.True(t, modTime.Equal(h.ModTime))
+
+	h = headers["dir1"]
+	be.Equal(t, "dir1/", h.Name)
+	be.Equal(t, tar.TypeDir, h.Typeflag)
+	be.Equal(t, 0, h.Size)
+
+	zh := zipHeaders["dir1/file3.txt"]
+	be.Equal(t, "dir1/file3.txt", zh.Name)
+	be.Equal(t, 5, zh.UncompressedSize64)
+	be.Equal(t, 0o644, zh.Mode().Perm())
+	be.True(t, modTime.Equal(zh.Modified))
+	zh = zipHeaders["dir1"]
+	be.Equal(t, "dir1/", zh.Name)
+	be.True(t, zh.Mode().IsDir())
+}
+
+func TestEntry_TarHeader_symlink(t *testing.T) {
+	dir := t.TempDir()
+	be.NilErr(t, os.WriteFile(filepath.Join(dir, "data.json"), nil, 0o644))
+	be.NilErr(t, os.Symlink("data.json", filepath.Join(dir, "link")))
+
+	tr := walker.New(nil, dir, walker.OnErrorHalt)
+	tr.Include(walker.MatchGlobName("link"))
+	files, err := tr.Collect()
+	be.NilErr(t, err)
+	be.Equal(t, 1, len(files))
+	for _, e := range files {
+		h, err := e.TarHeader()
+		be.NilErr(t, err)
+		be.Equal(t, "link", h.Name)
+		be.Equal(t, tar.TypeSymlink, h.Typeflag)
+		be.Equal(t, "data.json", h.Linkname)
+	}
+}
+
+func TestEntry_Key(t *testing.T) {
+	testFS := fstest.MapFS{
+		"a.txt":          &fstest.MapFile{},
+		"dir1/file3.txt": &fstest.MapFile{},
+		"dir1/file4.log": &fstest.MapFile{},
+	}
+	tr := walker.New(testFS, ".", walker.OnErrorHalt)
+	seen := make(map[string]walker.Entry)
+	for range 2 {
+		for e := range tr.Entries() {
+			seen[e.Key()] = e
+		}
+	}
+	be.NilErr(t, tr.Err())
+	be.Equal(t, 5, len(seen))
+	be.Equal(t, "file3.txt", seen["dir1/file3.txt"].Name())
+	be.Equal(t, "dir1/a.txt", walker.Entry{Path: "dir1/./a.txt"}.Key())
+}
+
+func TestEntry_OpenChild(t *testing.T) {
+	testFS := fstest.MapFS{
+		"dir1/file3.txt":   &fstest.MapFile{Data: []byte("hello, world\n")},
+		"dir1/sub/file.go": &fstest.MapFile{Data: []byte("package sub\n")},
+	}
+	temp := t.TempDir()
+	be.NilErr(t, os.CopyFS(temp, testFS))
+
+	for _, tr := range []walker.Ranger{
+		walker.New(testFS, ".", walker.OnErrorHalt),
+		walker.New(nil, temp, walker.OnErrorHalt),
+	} {
+		tr.Include(walker.MatchGlobName("dir1"))
+		dirs, err := tr.CollectEntries()
+		be.NilErr(t, err)
+		be.Equal(t, 1, len(dirs))
+		dir := dirs[0]
+
+		f, err := dir.OpenChild("file3.txt")
+		be.NilErr(t, err)
+		b, err := io.ReadAll(f)
+		be.NilErr(t, err)
+		be.NilErr(t, f.Close())
+		be.Equal(t, "hello, world\n", string(b))
+
+		f, err = dir.OpenChild("sub/file.go")
+		be.NilErr(t, err)
+		be.NilErr(t, f.Close())
+
+		_, err = dir.OpenChild("missing.txt")
+		be.True(t, errors.Is(err, fs.ErrNotExist))
+	}
+
+	tr := walker.New(testFS, ".", walker.OnErrorHalt)
+	files, err := tr.Collect()
+	be.NilErr(t, err)
+	_, err = files[0].OpenChild("x")
+	be.Nonzero(t, err)
+}
+
+func TestCompareBy(t *testing.T) {
+	then := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	testFS := fstest.MapFS{
+		"b.txt":        {Data: []byte("123"), ModTime: then.Add(2 * time.Hour)},
+		"dir1/a.txt":   {Data: []byte("1"), ModTime: then.Add(3 * time.Hour)},
+		"dir1/c.txt":   {Data: []byte("12345"), ModTime: then},
+		"dir2/d/e.txt": {Data: []byte("12"), ModTime: then.Add(time.Hour)},
+	}
+	tr := walker.New(testFS, ".", walker.OnErrorHalt)
+	entries, err := tr.Collect()
+	be.NilErr(t, err)
+	paths := func() string {
+		var paths []string
+		for _, e := range entries {
+			paths = append(paths, e.Path)
+		}
+		return strings.Join(paths, " ")
+	}
+	for _, tc := range []struct {
+		name string
+		cmp  func(a, b walker.Entry) int
+		want string
+	}{
+		{"path", walker.CompareByPath, "b.txt dir1/a.txt dir1/c.txt dir2/d/e.txt"},
+		{"name", walker.CompareByName, "dir1/a.txt b.txt dir1/c.txt dir2/d/e.txt"},
+		{"size", walker.CompareBySize, "dir1/a.txt dir2/d/e.txt b.txt dir1/c.txt"},
+		{"modtime", walker.CompareByModTime, "dir1/c.txt dir2/d/e.txt b.txt dir1/a.txt"},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			slices.SortFunc(entries, tc.cmp)
+			be.Equal(t, tc.want, paths())
+		})
+	}
+
+	// Entries without info sort first.
+	be.Equal(t, -1, walker.CompareBySize(walker.Entry{}, entries[0]))
+	be.Equal(t, -1, walker.CompareByModTime(walker.Entry{}, entries[0]))
+}
@@ -3,8 +3,14 @@ package walker
 import (
 	"errors"
 	"io/fs"
+	"slices"
+	"strings"
+	"sync"
 )
 
+// ErrMaxVisitsExceeded is reported by Err() when a walk is halted by MaxVisits.
+var ErrMaxVisitsExceeded = errors.New("walker: maximum visits exceeded")
+
 // ErrorPolicy is a function that returns
 // whether to continue (true) or halt (false) on error
 // by examining the error and the current Entry.
@@ -36,9 +42,115 @@ func OnErrorCollect(errs *[]error) ErrorPolicy {
 	}
 }
 
+// ErrorCollector collects errors like OnErrorCollect,
+// but is safe for concurrent use,
+// so one ErrorCollector can be shared by Rangers walking concurrently.
+// The zero value is ready to use.
+type ErrorCollector struct {
+	mu   sync.Mutex
+	errs []error
+}
+
+// Policy is an ErrorPolicy that appends err to the collected errors and continues.
+// Pass the method value c.Policy wherever an ErrorPolicy is expected.
+func (c *ErrorCollector) Policy(err error, _ Entry) bool {
+	c.Append(err)
+	return true
+}
+
+// Append adds err to the collected errors.
+func (c *ErrorCollector) Append(err error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.errs = append(c.errs, err)
+}
+
+// Errors returns a copy of the collected errors.
+func (c *ErrorCollector) Errors() []error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return slices.Clone(c.errs)
+}
+
 // OnErrPermissionIgnore is an ErrorPolicy
 // that continues if an error is fs.ErrPermission;
 // otherwise it halts on error.
 var OnErrPermissionIgnore ErrorPolicy = func(err error, e Entry) bool {
 	return errors.Is(err, fs.ErrPermission)
 }
+
+// WalkError wraps an error encountered while walking the filesystem,
+// such as a directory that could not be read.
+// Methods which call a user callback, such as Ranger.WalkErr and Ranger.ForEachDir,
+// return walk errors wrapped in a *WalkError
+// so that they can be told apart from a *CallbackError with errors.As.
+type WalkError struct {
+	Err error
+}
+
+// Error returns the message of the wrapped error,
+// prefixed with "walker: " unless it already is.
+func (e *WalkError) Error() string {
+	msg := e.Err.Error()
+	if strings.HasPrefix(msg, "walker: ") {
+		return msg
+	}
+	return "walker: " + msg
+}
+
+func (e *WalkError) Unwrap() error { return e.Err }
+
+// CallbackError wraps an error returned by a user callback,
+// such as the function passed to Ranger.WalkErr or Ranger.ForEachDir.
+// Path is the path of the Entry passed to the callback.
+type CallbackError struct {
+	Path string
+	Err  error
+}
+
+func (e *CallbackError) Error() string {
+	return "walker: callback failed for " + e.Path + ": " + e.Err.Error()
+}
+
+func (e *CallbackError) Unwrap() error { return e.Err }
+
+// walkError returns the Ranger's Err() wrapped in a *WalkError, if any.
+func (tr *Ranger) walkError() error {
+	if err := tr.Err(); err != nil {
+		return &WalkError{err}
+	}
+	return nil
+}
+
+// CoalesceErrors tells the Ranger to pass only the first error with a given cause
+// to the ErrorPolicy during each walk and to continue past the rest,
+// such as when every entry on an unavailable mount fails the same way.
+// The cause of an *fs.PathError is its Err field,
+// so errors for different paths with the same cause are coalesced;
+// other errors are compared with errors.Is.
+// Err() still reports the most recent error.
+func (tr *Ranger) CoalesceErrors() {
+	tr.coalesce = true
+}
+
+// handleError passes err for e to the ErrorPolicy
+// and reports whether to continue.
+// If CoalesceErrors is set and an error with the same cause
+// was already handled during this walk, it continues without calling the policy.
+func (tr *Ranger) handleError(err error, e Entry) bool {
+	if !tr.coalesce {
+		return tr.erp(err, e)
+	}
+	for _, cause := range tr.errCauses {
+		if errors.Is(err, cause) {
+			return true
+		}
+	}
+	cause := err
+	var pathErr *fs.PathError
+	if errors.As(err, &pathErr) {
+		cause = pathErr.Err
+	}
+	tr.errCauses = append(tr.errCauses, cause)
+	return tr.erp(err, e)
+}
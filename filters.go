@@ -2,18 +2,35 @@
 package walker
 
 import (
+	"io/fs"
+	"iter"
+	"os/user"
+	"path"
 	"path/filepath"
 	"regexp"
+	"strconv"
 	"strings"
+	"unicode/utf8"
 )
 
 // FilterFunc is a function type used to filter files and directories during the walk.
+//
+// The path based filters in this package compare against
+// the path with its separators converted to forward slashes
+// and convert the separators in their patterns the same way,
+// so patterns written with forward slashes work on every platform
+// for both fs.FS and OS walks.
 type FilterFunc func(Entry) bool
 
-// MatchRegexp returns true if the path matches the regular expression.
+// ErrFilterFunc is like FilterFunc, but it can report an error,
+// such as a failure to read a file, which is passed to the Ranger's ErrorPolicy.
+// See Ranger.IncludeErr and Ranger.ExcludeErr.
+type ErrFilterFunc func(Entry) (bool, error)
+
+// MatchRegexp returns true if the slash separated path matches the regular expression.
 func MatchRegexp(re *regexp.Regexp) FilterFunc {
 	return func(e Entry) bool {
-		return re.MatchString(e.Path)
+		return re.MatchString(e.slashPath())
 	}
 }
 
@@ -22,17 +39,61 @@ func MatchRegexpMust(re string) FilterFunc {
 	return MatchRegexp(regexp.MustCompile(re))
 }
 
+// MatchRegexpRel returns true if the slash separated path
+// relative to the walk root matches the regular expression.
+// Unlike MatchRegexp, anchored patterns such as `^dir1/`
+// work regardless of where the walk started.
+func MatchRegexpRel(re *regexp.Regexp) FilterFunc {
+	return func(e Entry) bool {
+		return re.MatchString(filepath.ToSlash(e.RelRoot()))
+	}
+}
+
+// MatchRegexpRelMust compiles re using regexp.MustCompile and passes it to MatchRegexpRel.
+func MatchRegexpRelMust(re string) FilterFunc {
+	return MatchRegexpRel(regexp.MustCompile(re))
+}
+
 // MatchGlobPath returns true if the path matches any of the glob patterns.
 func MatchGlobPath(patterns ...string) FilterFunc {
 	return func(e Entry) bool {
-		for _, pattern := range patterns {
-			matched, err := filepath.Match(pattern, e.Path)
-			if err == nil && matched {
-				return true
-			}
+		return matchSlashGlob(patterns, e.slashPath())
+	}
+}
+
+// MatchGlobPathInsensitive is like MatchGlobPath,
+// but the path and patterns are compared after converting them to lower case,
+// as on case-insensitive filesystems.
+func MatchGlobPathInsensitive(patterns ...string) FilterFunc {
+	lower := make([]string, len(patterns))
+	for i, pattern := range patterns {
+		lower[i] = strings.ToLower(pattern)
+	}
+	return func(e Entry) bool {
+		return matchSlashGlob(lower, strings.ToLower(e.slashPath()))
+	}
+}
+
+// MatchGlobRel returns true if the path relative to the walk root
+// matches any of the glob patterns.
+// Because patterns are compared in slash separated form,
+// the same pattern works for both fs.FS and OS walks.
+func MatchGlobRel(patterns ...string) FilterFunc {
+	return func(e Entry) bool {
+		return matchSlashGlob(patterns, filepath.ToSlash(e.RelRoot()))
+	}
+}
+
+// matchSlashGlob reports whether the slash separated name
+// matches any of the patterns after converting them to slash separated form.
+func matchSlashGlob(patterns []string, name string) bool {
+	for _, pattern := range patterns {
+		matched, err := path.Match(filepath.ToSlash(pattern), name)
+		if err == nil && matched {
+			return true
 		}
-		return false
 	}
+	return false
 }
 
 // MatchGlobName returns true if Entry.Name() matches any of the glob patterns.
@@ -48,6 +109,89 @@ func MatchGlobName(patterns ...string) FilterFunc {
 	}
 }
 
+// MatchGlobBrace is like MatchGlobName,
+// but first expands shell style braces in the patterns,
+// so that "*.{jpg,png}" matches both "a.jpg" and "a.png".
+// Braces may be nested, and an alternative may be empty,
+// so "a{,b}" matches "a" and "ab".
+// As in shells, braces without a comma, such as "{}" or "{a}",
+// and unbalanced braces are matched literally.
+func MatchGlobBrace(patterns ...string) FilterFunc {
+	var expanded []string
+	for _, pattern := range patterns {
+		expanded = append(expanded, expandBraces(pattern)...)
+	}
+	return MatchGlobName(expanded...)
+}
+
+// expandBraces returns the patterns produced by expanding the braces in p.
+func expandBraces(p string) []string {
+	for i := 0; i < len(p); i++ {
+		switch p[i] {
+		case '\\':
+			i++
+		case '{':
+			end, alts := braceAlternatives(p, i)
+			if end < 0 {
+				return []string{p}
+			}
+			var out []string
+			if len(alts) < 2 {
+				// Keep the brace literally and expand the rest.
+				for _, rest := range expandBraces(p[i+1:]) {
+					out = append(out, p[:i+1]+rest)
+				}
+				return out
+			}
+			suffixes := expandBraces(p[end+1:])
+			for _, alt := range alts {
+				for _, a := range expandBraces(alt) {
+					for _, suffix := range suffixes {
+						out = append(out, p[:i]+a+suffix)
+					}
+				}
+			}
+			return out
+		}
+	}
+	return []string{p}
+}
+
+// braceAlternatives finds the brace matching the one at p[start]
+// and returns its index along with the comma separated alternatives inside.
+// It returns -1 if the brace is unbalanced.
+func braceAlternatives(p string, start int) (int, []string) {
+	depth := 0
+	last := start + 1
+	var alts []string
+	for i := start; i < len(p); i++ {
+		switch p[i] {
+		case '\\':
+			i++
+		case '{':
+			depth++
+		case '}':
+			depth--
+			if depth == 0 {
+				return i, append(alts, p[last:i])
+			}
+		case ',':
+			if depth == 1 {
+				alts = append(alts, p[last:i])
+				last = i + 1
+			}
+		}
+	}
+	return -1, nil
+}
+
+// MatchGlob returns true if either Entry.Name() or the path
+// matches any of the glob patterns.
+// Use MatchGlobName or MatchGlobPath to match only one or the other.
+func MatchGlob(patterns ...string) FilterFunc {
+	return Or(MatchGlobName(patterns...), MatchGlobPath(patterns...))
+}
+
 // MatchExtension creates a FilterFunc that filters files based on their extensions.
 // It returns true if the file has any of the specified extensions.
 // It is case insensitive.
@@ -66,10 +210,174 @@ func MatchExtension(extensions ...string) FilterFunc {
 	}
 }
 
+// MatchInPathSegment returns true if any slash or backslash separated
+// component of the path exactly equals one of the segments.
+func MatchInPathSegment(segments ...string) FilterFunc {
+	return func(e Entry) bool {
+		for _, part := range splitPath(e.Path) {
+			for _, segment := range segments {
+				if part == segment {
+					return true
+				}
+			}
+		}
+		return false
+	}
+}
+
+func isSeparator(r rune) bool {
+	return r == '/' || r == '\\'
+}
+
+// splitPath splits p into its components,
+// accepting both slash and backslash as separators.
+func splitPath(p string) []string {
+	return strings.FieldsFunc(p, isSeparator)
+}
+
+// MatchParentName returns true if the name of the directory
+// containing the Entry is one of names.
+// For a directory Entry, this is the name of its parent directory,
+// not its own name.
+func MatchParentName(names ...string) FilterFunc {
+	return func(e Entry) bool {
+		parent := filepath.Base(e.parent())
+		for _, name := range names {
+			if parent == name {
+				return true
+			}
+		}
+		return false
+	}
+}
+
+// InDir returns a FilterFunc that matches entries
+// whose containing directory matches dirPred,
+// so directory filters can be combined with file filters using And and Or.
+// dirPred is passed an Entry for the directory containing the entry,
+// so for a directory it sees the parent, not the directory itself.
+// The directory is only stat'ed if dirPred calls Info.
+func InDir(dirPred FilterFunc) FilterFunc {
+	return func(e Entry) bool {
+		return dirPred(e.parentEntry())
+	}
+}
+
+// MatchNameEqualsParent returns true if Entry.Base()
+// equals the name of the directory containing the Entry,
+// as in "foo/foo.go" when stripExt is true,
+// or "foo/foo" when stripExt is false.
+// If stripExt is true, the extension is removed from Entry.Base()
+// before comparing.
+func MatchNameEqualsParent(stripExt bool) FilterFunc {
+	return func(e Entry) bool {
+		name := e.Base()
+		if stripExt {
+			name = strings.TrimSuffix(name, e.Ext())
+		}
+		return name == filepath.Base(e.parent())
+	}
+}
+
+// MatchComponentCount returns true if the number of components
+// in the path relative to the walk root is between min and max inclusive.
+// The root itself has zero components
+// and an entry directly inside the root has one,
+// so this is equivalent to filtering by depth.
+// Pass -1 for min or max to leave that end unbounded.
+func MatchComponentCount(min, max int) FilterFunc {
+	return func(e Entry) bool {
+		return inRange(len(e.relSegments()), min, max)
+	}
+}
+
+// inRange reports whether n is between min and max inclusive,
+// treating a negative bound as unbounded.
+func inRange(n, min, max int) bool {
+	return (min < 0 || n >= min) && (max < 0 || n <= max)
+}
+
+// MatchNameLength returns true if the length in bytes of Entry.Base()
+// is between min and max inclusive.
+// Pass -1 for min or max to leave that end unbounded.
+func MatchNameLength(min, max int) FilterFunc {
+	return func(e Entry) bool {
+		return inRange(len(e.Base()), min, max)
+	}
+}
+
+// MatchNameRuneLength is like MatchNameLength
+// but counts runes instead of bytes.
+func MatchNameRuneLength(min, max int) FilterFunc {
+	return func(e Entry) bool {
+		return inRange(utf8.RuneCountInString(e.Base()), min, max)
+	}
+}
+
+// MatchPathLongerThan returns true if the length in bytes of Entry.Path
+// is greater than n.
+func MatchPathLongerThan(n int) FilterFunc {
+	return func(e Entry) bool {
+		return len(e.Path) > n
+	}
+}
+
+// MatchWindowsLongPath reports whether Entry.Path is too long
+// for Windows programs limited to MAX_PATH,
+// which is 260 characters including a terminating null.
+// It is useful for auditing a tree before copying it to Windows,
+// but the length is measured in bytes of the path being walked,
+// so the destination path may differ.
+var MatchWindowsLongPath FilterFunc = MatchPathLongerThan(259)
+
+// MatchCompoundExtension creates a FilterFunc that matches files
+// whose base name ends with any of the specified extensions,
+// such as ".tar.gz" or ".d.ts", which MatchExtension cannot match
+// because Entry.Ext only returns the final extension.
+// It is case insensitive.
+func MatchCompoundExtension(extensions ...string) FilterFunc {
+	lower := make([]string, len(extensions))
+	for i, ext := range extensions {
+		lower[i] = strings.ToLower(ext)
+	}
+	return func(e Entry) bool {
+		name := strings.ToLower(e.Base())
+		for _, ext := range lower {
+			if strings.HasSuffix(name, ext) {
+				return true
+			}
+		}
+		return false
+	}
+}
+
+// ExtensionSet is a set of lowercase file extensions.
+// Its Match method is equivalent to MatchExtension
+// but checks membership in constant time,
+// so it is faster for long lists of extensions.
+type ExtensionSet map[string]struct{}
+
+// NewExtensionSet returns an ExtensionSet containing extensions.
+func NewExtensionSet(extensions ...string) ExtensionSet {
+	set := make(ExtensionSet, len(extensions))
+	for _, ext := range extensions {
+		set[strings.ToLower(ext)] = struct{}{}
+	}
+	return set
+}
+
+// Match is a FilterFunc that reports whether the Entry's extension is in the set.
+// It is case insensitive.
+func (set ExtensionSet) Match(e Entry) bool {
+	_, ok := set[strings.ToLower(e.Ext())]
+	return ok
+}
+
 // MatchPrefixPath creates a FilterFunc that matches paths starting with the given prefix.
 func MatchPrefixPath(prefix string) FilterFunc {
+	prefix = filepath.ToSlash(prefix)
 	return func(e Entry) bool {
-		return strings.HasPrefix(e.Path, prefix)
+		return strings.HasPrefix(e.slashPath(), prefix)
 	}
 }
 
@@ -77,14 +385,137 @@ func MatchPrefixPath(prefix string) FilterFunc {
 // that matches if Entry.Name() starts with the given prefix.
 func MatchPrefixName(prefix string) FilterFunc {
 	return func(e Entry) bool {
-		return strings.HasPrefix(e.Path, prefix)
+		return strings.HasPrefix(e.Name(), prefix)
 	}
 }
 
 // MatchDotFile reports whether an Entry.Name() begins with a dot.
-var MatchDotFile FilterFunc = MatchPrefixName(".")
+// The names "." and "..", as of a walk rooted at ".", do not match.
+var MatchDotFile FilterFunc = func(e Entry) bool {
+	name := e.Name()
+	return strings.HasPrefix(name, ".") && name != "." && name != ".."
+}
+
+// MatchValidUTF8Name reports whether Entry.Base() is valid UTF-8.
+var MatchValidUTF8Name FilterFunc = func(e Entry) bool {
+	return utf8.ValidString(e.Base())
+}
+
+// MatchInvalidUTF8Name reports whether Entry.Base() is not valid UTF-8.
+var MatchInvalidUTF8Name FilterFunc = Not(MatchValidUTF8Name)
+
+// MatchMode returns a FilterFunc that matches entries
+// whose type bits from DirEntry.Type(), masked by mask, equal want.
+// For example, MatchMode(fs.ModeType, fs.ModeDir|fs.ModeSymlink) would match
+// only followed links to directories.
+// It does not call Info(), so permission bits are never set,
+// and entries with a nil DirEntry do not match.
+// A symbolic link is reported as a link rather than as its target,
+// so a link to a regular file is not regular;
+// with FollowSymlinks, Type() includes both fs.ModeSymlink and the target's type.
+func MatchMode(mask, want fs.FileMode) FilterFunc {
+	return func(e Entry) bool {
+		return e.DirEntry != nil && e.ModeType()&mask == want
+	}
+}
+
+// MatchRegular reports whether an Entry is a regular file,
+// that is, whether its DirEntry.Type() has no type bits set.
+// A symbolic link to a regular file does not match.
+// See MatchMode.
+var MatchRegular FilterFunc = MatchMode(fs.ModeType, 0)
+
+// MatchSymlink reports whether an Entry is itself a symbolic link,
+// regardless of what the link points to.
+// See MatchMode and Entry.IsSymlink.
+var MatchSymlink FilterFunc = MatchMode(fs.ModeSymlink, fs.ModeSymlink)
+
+// MatchDevice reports whether an Entry is a device file.
+// See Entry.IsDevice.
+var MatchDevice FilterFunc = Entry.IsDevice
+
+// MatchNamedPipe reports whether an Entry is a named pipe (FIFO).
+// It is equivalent to MatchMode(fs.ModeNamedPipe, fs.ModeNamedPipe).
+// See Entry.IsNamedPipe.
+var MatchNamedPipe FilterFunc = Entry.IsNamedPipe
+
+// MatchSocket reports whether an Entry is a Unix domain socket.
+// See Entry.IsSocket.
+var MatchSocket FilterFunc = Entry.IsSocket
+
+// MatchPermExact returns a FilterFunc that matches entries
+// whose permission bits are exactly perm.
+// It calls Info(), and entries whose info cannot be read do not match.
+func MatchPermExact(perm fs.FileMode) FilterFunc {
+	perm = perm.Perm()
+	return func(e Entry) bool {
+		if e.DirEntry == nil {
+			return false
+		}
+		info, err := e.DirEntry.Info()
+		return err == nil && info.Mode().Perm() == perm
+	}
+}
+
+// MatchInfo returns a FilterFunc that calls Info()
+// and passes the result to pred,
+// so several metadata fields can be checked together in one place.
+// Entries whose info cannot be read do not match.
+func MatchInfo(pred func(fs.FileInfo) bool) FilterFunc {
+	return func(e Entry) bool {
+		if e.DirEntry == nil {
+			return false
+		}
+		info, err := e.DirEntry.Info()
+		return err == nil && pred(info)
+	}
+}
+
+// MatchOwnedByName returns a FilterFunc that matches entries
+// owned by the user with the given username.
+// The username is looked up once with os/user.
+// If the lookup fails, or on platforms where MatchOwnedByUID is unsupported,
+// the FilterFunc never matches.
+func MatchOwnedByName(username string) FilterFunc {
+	u, err := user.Lookup(username)
+	if err != nil {
+		return func(Entry) bool { return false }
+	}
+	uid, err := strconv.Atoi(u.Uid)
+	if err != nil {
+		return func(Entry) bool { return false }
+	}
+	return MatchOwnedByUID(uid)
+}
+
+// MatchDotDir reports whether an Entry is a directory whose name begins with a dot.
+// Unlike MatchDotFile, it never matches files,
+// so it can exclude directories such as .git
+// while keeping files such as .gitignore.
+var MatchDotDir FilterFunc = func(e Entry) bool {
+	name := e.Name()
+	return e.IsDir() && strings.HasPrefix(name, ".") && name != "." && name != ".."
+}
+
+// MatchHidden reports whether an Entry is hidden.
+// Entries whose name begins with a dot are hidden on every platform.
+// On macOS, entries with the UF_HIDDEN flag, as set by "chflags hidden",
+// and on Windows, entries with the hidden file attribute
+// are hidden as well.
+// Checking the flags calls Info() and only works for filesystems that expose them,
+// such as the OS filesystem.
+var MatchHidden FilterFunc = func(e Entry) bool {
+	name := e.Name()
+	if strings.HasPrefix(name, ".") && name != "." && name != ".." {
+		return true
+	}
+	return hasHiddenAttr(e)
+}
 
 // And chains FilterFuncs and returns whether they are all true.
+// Filters are evaluated left to right
+// and evaluation stops at the first filter that returns false,
+// so cheap filters should come before expensive ones.
 func And(filters ...FilterFunc) FilterFunc {
 	return func(e Entry) bool {
 		for _, f := range filters {
@@ -97,6 +528,9 @@ func And(filters ...FilterFunc) FilterFunc {
 }
 
 // Or chains FilterFuncs and returns whether at least one is true.
+// Filters are evaluated left to right
+// and evaluation stops at the first filter that returns true,
+// so cheap filters should come before expensive ones.
 func Or(filters ...FilterFunc) FilterFunc {
 	return func(e Entry) bool {
 		for _, f := range filters {
@@ -114,3 +548,106 @@ func Not(f FilterFunc) FilterFunc {
 		return !f(e)
 	}
 }
+
+// Memoize wraps f so that it is called at most once per Entry.Path,
+// returning the cached result for later calls.
+// It assumes that the filesystem does not change while the cache is in use.
+// The cache lives as long as the returned FilterFunc,
+// so its memory grows with the number of distinct paths checked,
+// and the FilterFunc is not safe for concurrent use.
+func Memoize(f FilterFunc) FilterFunc {
+	cache := make(map[string]bool)
+	return func(e Entry) bool {
+		if matched, ok := cache[e.Path]; ok {
+			return matched
+		}
+		matched := f(e)
+		cache[e.Path] = matched
+		return matched
+	}
+}
+
+// FilterEntries returns a sequence of the Entries in seq that match f.
+// It can be used to further filter the output of a Ranger,
+// or of a collected slice via slices.Values, without walking the tree again.
+func FilterEntries(seq iter.Seq[Entry], f FilterFunc) iter.Seq[Entry] {
+	return func(yield func(Entry) bool) {
+		for e := range seq {
+			if f(e) && !yield(e) {
+				return
+			}
+		}
+	}
+}
+
+// MatchSample returns a stateful FilterFunc
+// that matches every Nth file it is called with,
+// starting with the Nth.
+// Directories always match and are not counted,
+// so the sample is taken from files alone.
+// Because the result depends on the order of calls,
+// the sample is only deterministic for a single sequential walk
+// of an unchanged tree, and the FilterFunc is not safe for concurrent use.
+// The count carries over between walks,
+// so create a new FilterFunc for each walk.
+// If everyN is less than 2, every file matches.
+func MatchSample(everyN int) FilterFunc {
+	n := 0
+	return func(e Entry) bool {
+		if e.IsDir() || everyN < 2 {
+			return true
+		}
+		n++
+		if n < everyN {
+			return false
+		}
+		n = 0
+		return true
+	}
+}
+
+// FilterSet is a reusable group of filters that can be applied to a Ranger.
+// Nil fields are ignored by Apply.
+type FilterSet struct {
+	Include, Exclude       FilterFunc
+	IncludeDir, ExcludeDir FilterFunc
+}
+
+// Apply sets the non-nil filters of set on tr.
+func (set FilterSet) Apply(tr *Ranger) {
+	if set.Include != nil {
+		tr.Include(set.Include)
+	}
+	if set.Exclude != nil {
+		tr.Exclude(set.Exclude)
+	}
+	if set.IncludeDir != nil {
+		tr.IncludeDir(set.IncludeDir)
+	}
+	if set.ExcludeDir != nil {
+		tr.ExcludeDir(set.ExcludeDir)
+	}
+}
+
+// MatchInList returns a FilterFunc that matches entries
+// whose path relative to the root of the walk is in paths,
+// such as the files listed in a manifest or lockfile.
+// Paths are compared after cleaning and converting to forward slashes,
+// using a set, so long lists of paths remain fast.
+func MatchInList(paths []string) FilterFunc {
+	set := make(map[string]struct{}, len(paths))
+	for _, p := range paths {
+		set[filepath.ToSlash(filepath.Clean(p))] = struct{}{}
+	}
+	return func(e Entry) bool {
+		_, ok := set[filepath.ToSlash(e.RelRoot())]
+		return ok
+	}
+}
+
+// MatchNotInList returns a FilterFunc that matches entries
+// whose path relative to the root of the walk is not in paths.
+// It is the inverse of MatchInList.
+func MatchNotInList(paths []string) FilterFunc {
+	return Not(MatchInList(paths))
+}
@@ -0,0 +1,48 @@
+package walker_test
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/carlmjohnson/be"
+	"github.com/earthboundkid/walker"
+)
+
+func TestMatchHidden_flag(t *testing.T) {
+	const ufHidden = 0x8000
+	dir := t.TempDir()
+	be.NilErr(t, os.WriteFile(filepath.Join(dir, ".dotfile"), nil, 0o644))
+	be.NilErr(t, os.WriteFile(filepath.Join(dir, "flagged.txt"), nil, 0o644))
+	be.NilErr(t, os.WriteFile(filepath.Join(dir, "visible.txt"), nil, 0o644))
+	be.NilErr(t, syscall.Chflags(filepath.Join(dir, "flagged.txt"), ufHidden))
+
+	tr := walker.New(nil, dir, walker.OnErrorHalt)
+	tr.Include(walker.MatchHidden)
+	var names []string
+	for path := range tr.FilePaths() {
+		names = append(names, filepath.Base(path))
+	}
+	be.NilErr(t, tr.Err())
+	be.Equal(t, ".dotfile; flagged.txt", strings.Join(names, "; "))
+}
+
+func TestMatchCreated(t *testing.T) {
+	dir := t.TempDir()
+	start := time.Now().Add(-time.Minute)
+	be.NilErr(t, os.WriteFile(filepath.Join(dir, "new.txt"), nil, 0o644))
+	// Changing the modification time does not change the creation time.
+	old := start.Add(-24 * time.Hour)
+	be.NilErr(t, os.Chtimes(filepath.Join(dir, "new.txt"), old, old))
+
+	tr := walker.New(nil, dir, walker.OnErrorHalt)
+	tr.Include(walker.MatchCreatedAfter(start))
+	be.Equal(t, 1, len(slices.Collect(tr.FilePaths())))
+
+	tr.Include(walker.MatchCreatedBefore(start))
+	be.Equal(t, 0, len(slices.Collect(tr.FilePaths())))
+}
@@ -0,0 +1,35 @@
+package walker_test
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/carlmjohnson/be"
+	"github.com/earthboundkid/walker"
+	"golang.org/x/sys/unix"
+)
+
+func TestMatchCreated(t *testing.T) {
+	dir := t.TempDir()
+	start := time.Now().Add(-time.Minute)
+	name := filepath.Join(dir, "new.txt")
+	be.NilErr(t, os.WriteFile(name, nil, 0o644))
+	var stx unix.Statx_t
+	if err := unix.Statx(unix.AT_FDCWD, name, 0, unix.STATX_BTIME, &stx); err != nil ||
+		stx.Mask&unix.STATX_BTIME == 0 {
+		t.Skip("filesystem does not record creation times")
+	}
+	// Changing the modification time does not change the creation time.
+	old := start.Add(-24 * time.Hour)
+	be.NilErr(t, os.Chtimes(name, old, old))
+
+	tr := walker.New(nil, dir, walker.OnErrorHalt)
+	tr.Include(walker.MatchCreatedAfter(start))
+	be.Equal(t, 1, len(slices.Collect(tr.FilePaths())))
+
+	tr.Include(walker.MatchCreatedBefore(start))
+	be.Equal(t, 0, len(slices.Collect(tr.FilePaths())))
+}
@@ -0,0 +1,639 @@
+package walker_test
+
+import (
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+	"testing/fstest"
+
+	"github.com/carlmjohnson/be"
+	"github.com/earthboundkid/walker"
+)
+
+func TestMatchInPathSegment(t *testing.T) {
+	match := walker.MatchInPathSegment("test")
+	for _, tc := range []struct {
+		path string
+		want bool
+	}{
+		{"a/test/b", true},
+		{"test", true},
+		{"a/test", true},
+		{`a\test\b`, true},
+		{"a/testing/b", false},
+		{"a/contest/b", false},
+		{"a/b", false},
+	} {
+		be.Equal(t, tc.want, match(walker.Entry{Path: tc.path}))
+	}
+}
+
+func TestAndOr_shortCircuit(t *testing.T) {
+	calls := 0
+	expensive := func(walker.Entry) bool {
+		calls++
+		return true
+	}
+	e := walker.Entry{Path: "a.log"}
+
+	be.False(t, walker.And(walker.MatchExtension(".txt"), expensive)(e))
+	be.Equal(t, 0, calls)
+	be.True(t, walker.Or(walker.MatchExtension(".log"), expensive)(e))
+	be.Equal(t, 0, calls)
+
+	be.True(t, walker.And(walker.MatchExtension(".log"), expensive)(e))
+	be.Equal(t, 1, calls)
+	be.True(t, walker.Or(walker.MatchExtension(".txt"), expensive)(e))
+	be.Equal(t, 2, calls)
+}
+
+func TestMatchGlob(t *testing.T) {
+	testFS := fstest.MapFS{
+		"a.txt":                &fstest.MapFile{},
+		"dir1/file3.txt":       &fstest.MapFile{},
+		"dir1/file4.log":       &fstest.MapFile{},
+		"dir2/file5.txt":       &fstest.MapFile{},
+		"dir2/subdir/file6.go": &fstest.MapFile{},
+	}
+	tr := walker.New(testFS, ".", walker.OnErrorHalt)
+	tr.Include(walker.MatchGlob("*.go", "dir1/*"))
+	paths := slices.Collect(tr.FilePaths())
+	be.NilErr(t, tr.Err())
+	be.Equal(t, "dir1/file3.txt; dir1/file4.log; dir2/subdir/file6.go", strings.Join(paths, "; "))
+}
+
+func TestFilterSet(t *testing.T) {
+	testFS := fstest.MapFS{
+		"a.txt":                &fstest.MapFile{},
+		"dir1/file3.txt":       &fstest.MapFile{},
+		"dir1/file4.log":       &fstest.MapFile{},
+		"dir2/file5.txt":       &fstest.MapFile{},
+		"dir2/subdir/file6.go": &fstest.MapFile{},
+	}
+	textFiles := walker.FilterSet{
+		Include:    walker.MatchExtension(".txt"),
+		ExcludeDir: walker.MatchGlobName("dir2"),
+	}
+
+	tr1 := walker.New(testFS, ".", walker.OnErrorHalt)
+	textFiles.Apply(&tr1)
+	paths := slices.Collect(tr1.FilePaths())
+	be.Equal(t, "a.txt; dir1/file3.txt", strings.Join(paths, "; "))
+
+	tr2 := walker.New(testFS, "dir1", walker.OnErrorHalt)
+	textFiles.Apply(&tr2)
+	paths = slices.Collect(tr2.FilePaths())
+	be.Equal(t, "dir1/file3.txt", strings.Join(paths, "; "))
+}
+
+func TestMatchComponentCount(t *testing.T) {
+	testFS := fstest.MapFS{
+		"a.txt":                &fstest.MapFile{},
+		"dir1/file3.txt":       &fstest.MapFile{},
+		"dir2/file5.txt":       &fstest.MapFile{},
+		"dir2/subdir/file6.go": &fstest.MapFile{},
+	}
+	for _, tc := range []struct {
+		root     string
+		min, max int
+		want     string
+	}{
+		{".", -1, -1, ". a.txt dir1 dir1/file3.txt dir2 dir2/file5.txt dir2/subdir dir2/subdir/file6.go"},
+		{".", 0, 0, "."},
+		{".", 1, 1, "a.txt dir1 dir2"},
+		{".", 2, -1, "dir1/file3.txt dir2/file5.txt dir2/subdir dir2/subdir/file6.go"},
+		{".", -1, 1, ". a.txt dir1 dir2"},
+		{"dir2", 1, 1, "dir2/file5.txt dir2/subdir"},
+	} {
+		tr := walker.New(testFS, tc.root, walker.OnErrorHalt)
+		tr.Include(walker.MatchComponentCount(tc.min, tc.max))
+		var paths []string
+		for e := range tr.Entries() {
+			paths = append(paths, e.Path)
+		}
+		be.Equal(t, tc.want, strings.Join(paths, " "))
+	}
+}
+
+func TestMatchGlobRel(t *testing.T) {
+	testFS := fstest.MapFS{
+		"a.txt":                &fstest.MapFile{},
+		"dir1/file3.txt":       &fstest.MapFile{},
+		"dir1/file4.log":       &fstest.MapFile{},
+		"dir2/file5.txt":       &fstest.MapFile{},
+		"dir2/subdir/file6.go": &fstest.MapFile{},
+	}
+	temp := t.TempDir()
+	be.NilErr(t, os.CopyFS(temp, testFS))
+
+	for _, tr := range []walker.Ranger{
+		walker.New(testFS, ".", walker.OnErrorHalt),
+		walker.New(nil, temp, walker.OnErrorHalt),
+	} {
+		tr.Include(walker.MatchGlobRel("dir1/*.txt", "dir2/*/*.go"))
+		var paths []string
+		for e := range tr.FileEntries() {
+			paths = append(paths, filepath.ToSlash(e.RelRoot()))
+		}
+		be.NilErr(t, tr.Err())
+		be.Equal(t, "dir1/file3.txt; dir2/subdir/file6.go", strings.Join(paths, "; "))
+	}
+}
+
+func TestMatchNameLength(t *testing.T) {
+	long := strings.Repeat("x", 256)
+	for _, tc := range []struct {
+		path     string
+		min, max int
+		bytes    bool
+		runes    bool
+	}{
+		{"dir/short.txt", -1, 255, true, true},
+		{"dir/" + long, -1, 255, false, false},
+		{"dir/" + long, 256, -1, true, true},
+		{"dir/héllo", 6, 6, true, false},
+		{"dir/héllo", 5, 5, false, true},
+	} {
+		e := walker.Entry{Path: tc.path}
+		be.Equal(t, tc.bytes, walker.MatchNameLength(tc.min, tc.max)(e))
+		be.Equal(t, tc.runes, walker.MatchNameRuneLength(tc.min, tc.max)(e))
+	}
+}
+
+func TestExtensionSet(t *testing.T) {
+	exts := []string{".go", ".TXT", ".tar", ".md"}
+	set := walker.NewExtensionSet(exts...)
+	match := walker.MatchExtension(exts...)
+	for _, path := range []string{
+		"a.go", "a.txt", "A.TXT", "a.Md", "a.tar.gz", "a.tar", "a", "dir.go/a", ".go",
+	} {
+		e := walker.Entry{Path: path}
+		be.Equal(t, match(e), set.Match(e))
+	}
+}
+
+func BenchmarkExtensionSet(b *testing.B) {
+	exts := slices.Clone(walker.SourceCodeExtensions)
+	for i := range 200 {
+		exts = append(exts, fmt.Sprintf(".ext%d", i))
+	}
+	e := walker.Entry{Path: "dir/file.zig"}
+	b.Run("MatchExtension", func(b *testing.B) {
+		match := walker.MatchExtension(slices.Clone(exts)...)
+		for range b.N {
+			match(e)
+		}
+	})
+	b.Run("ExtensionSet", func(b *testing.B) {
+		match := walker.FilterFunc(walker.NewExtensionSet(exts...).Match)
+		for range b.N {
+			match(e)
+		}
+	})
+}
+
+func TestMatchCompoundExtension(t *testing.T) {
+	tarGz := walker.MatchCompoundExtension(".tar.gz", ".d.ts")
+	gz := walker.MatchExtension(".gz")
+	for _, tc := range []struct {
+		path        string
+		tarGz, isGz bool
+	}{
+		{"dir/backup.tar.gz", true, true},
+		{"dir/BACKUP.TAR.GZ", true, true},
+		{"dir/notes.gz", false, true},
+		{"dir/types.d.ts", true, false},
+		{"dir/main.ts", false, false},
+		{"dir.tar.gz/file", false, false},
+	} {
+		e := walker.Entry{Path: tc.path}
+		be.Equal(t, tc.tarGz, tarGz(e))
+		be.Equal(t, tc.isGz, gz(e))
+	}
+}
+
+func TestMemoize(t *testing.T) {
+	testFS := fstest.MapFS{
+		"a.txt":          &fstest.MapFile{},
+		"dir1/file3.txt": &fstest.MapFile{},
+		"dir1/file4.log": &fstest.MapFile{},
+	}
+	calls := 0
+	expensive := func(e walker.Entry) bool {
+		calls++
+		return strings.HasSuffix(e.Path, ".txt")
+	}
+
+	tr := walker.New(testFS, ".", walker.OnErrorHalt)
+	tr.Include(expensive)
+	for range 3 {
+		be.Equal(t, "a.txt; dir1/file3.txt", strings.Join(slices.Collect(tr.FilePaths()), "; "))
+	}
+	be.Equal(t, 15, calls)
+
+	calls = 0
+	tr.Include(walker.Memoize(expensive))
+	for range 3 {
+		be.Equal(t, "a.txt; dir1/file3.txt", strings.Join(slices.Collect(tr.FilePaths()), "; "))
+	}
+	be.Equal(t, 5, calls)
+}
+
+func TestMatchPrefixName(t *testing.T) {
+	testFS := fstest.MapFS{
+		".gitignore":            &fstest.MapFile{},
+		"a.txt":                 &fstest.MapFile{},
+		"dir1/.hidden":          &fstest.MapFile{},
+		"dir1/file3.txt":        &fstest.MapFile{},
+		"dir2/subdir/file6.go":  &fstest.MapFile{},
+		"dir2/subdir/.cache/x":  &fstest.MapFile{},
+		"file-dir/notfile.txt":  &fstest.MapFile{},
+		"x.file/deeper/file.md": &fstest.MapFile{},
+	}
+	temp := t.TempDir()
+	be.NilErr(t, os.CopyFS(temp, testFS))
+
+	for _, tc := range []struct {
+		name   string
+		filter walker.FilterFunc
+		want   string
+	}{
+		{"base name", walker.MatchPrefixName("file"),
+			"dir1/file3.txt; dir2/subdir/file6.go; x.file/deeper/file.md"},
+		{"dot file", walker.MatchDotFile,
+			".gitignore; dir1/.hidden"},
+		{"no match", walker.MatchPrefixName("dir"), ""},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			for _, tr := range []walker.Ranger{
+				walker.New(testFS, ".", walker.OnErrorHalt),
+				walker.New(nil, temp, walker.OnErrorHalt),
+			} {
+				tr.Include(tc.filter)
+				var paths []string
+				for e := range tr.FileEntries() {
+					paths = append(paths, filepath.ToSlash(e.RelRoot()))
+				}
+				be.NilErr(t, tr.Err())
+				be.Equal(t, tc.want, strings.Join(paths, "; "))
+			}
+		})
+	}
+
+	tr := walker.New(testFS, ".", walker.OnErrorHalt)
+	tr.ExcludeHidden()
+	paths := slices.Collect(tr.FilePaths())
+	be.NilErr(t, tr.Err())
+	be.Equal(t, "a.txt; dir1/file3.txt; dir2/subdir/file6.go; file-dir/notfile.txt; x.file/deeper/file.md",
+		strings.Join(paths, "; "))
+}
+
+func TestMatchDotDir(t *testing.T) {
+	testFS := fstest.MapFS{
+		".git/config":    &fstest.MapFile{},
+		".gitignore":     &fstest.MapFile{},
+		"a.txt":          &fstest.MapFile{},
+		"dir1/.hidden":   &fstest.MapFile{},
+		"dir1/.cache/x":  &fstest.MapFile{},
+		"dir1/file3.txt": &fstest.MapFile{},
+	}
+	temp := t.TempDir()
+	be.NilErr(t, os.CopyFS(temp, testFS))
+
+	for _, tr := range []walker.Ranger{
+		walker.New(testFS, ".", walker.OnErrorHalt),
+		walker.New(nil, temp, walker.OnErrorHalt),
+	} {
+		tr.ExcludeDir(walker.MatchDotDir)
+		var paths []string
+		for e := range tr.FileEntries() {
+			paths = append(paths, filepath.ToSlash(e.RelRoot()))
+		}
+		be.NilErr(t, tr.Err())
+		be.Equal(t, ".gitignore; a.txt; dir1/.hidden; dir1/file3.txt", strings.Join(paths, "; "))
+	}
+}
+
+func TestMatchParentName(t *testing.T) {
+	testFS := fstest.MapFS{
+		"a.txt":                 &fstest.MapFile{},
+		"dir2/file5.txt":        &fstest.MapFile{},
+		"dir2/subdir/file6.go":  &fstest.MapFile{},
+		"dir2/subdir/deeper/x":  &fstest.MapFile{},
+		"dir3/subdir/file7.txt": &fstest.MapFile{},
+	}
+	temp := t.TempDir()
+	be.NilErr(t, os.CopyFS(temp, testFS))
+
+	for _, tr := range []walker.Ranger{
+		walker.New(testFS, ".", walker.OnErrorHalt),
+		walker.New(nil, temp, walker.OnErrorHalt),
+	} {
+		tr.Include(walker.MatchParentName("subdir"))
+		var paths []string
+		for e := range tr.Entries() {
+			paths = append(paths, filepath.ToSlash(e.RelRoot()))
+		}
+		be.NilErr(t, tr.Err())
+		be.Equal(t, "dir2/subdir/deeper; dir2/subdir/file6.go; dir3/subdir/file7.txt", strings.Join(paths, "; "))
+	}
+}
+
+func TestInDir(t *testing.T) {
+	testFS := fstest.MapFS{
+		"a.txt":                 &fstest.MapFile{},
+		"dir1/file3.txt":        &fstest.MapFile{},
+		"dir1/file4.log":        &fstest.MapFile{},
+		"dir2/subdir/file6.txt": &fstest.MapFile{},
+		"other/file7.txt":       &fstest.MapFile{},
+	}
+	temp := t.TempDir()
+	be.NilErr(t, os.CopyFS(temp, testFS))
+
+	for _, tr := range []walker.Ranger{
+		walker.New(testFS, ".", walker.OnErrorHalt),
+		walker.New(nil, temp, walker.OnErrorHalt),
+	} {
+		tr.Include(walker.And(
+			walker.MatchExtension(".txt"),
+			walker.InDir(walker.MatchGlobName("dir*")),
+		))
+		var paths []string
+		for e := range tr.FileEntries() {
+			paths = append(paths, filepath.ToSlash(e.RelRoot()))
+		}
+		be.NilErr(t, tr.Err())
+		be.Equal(t, "dir1/file3.txt", strings.Join(paths, "; "))
+
+		tr.Include(walker.InDir(walker.MatchInfo(fs.FileInfo.IsDir)))
+		paths = nil
+		for e := range tr.FileEntries() {
+			paths = append(paths, filepath.ToSlash(e.RelRoot()))
+		}
+		be.NilErr(t, tr.Err())
+		be.Equal(t, 5, len(paths))
+	}
+}
+
+func TestMatchRegexpRel(t *testing.T) {
+	testFS := fstest.MapFS{
+		"a.txt":                &fstest.MapFile{},
+		"dir1/file3.txt":       &fstest.MapFile{},
+		"dir1/file4.log":       &fstest.MapFile{},
+		"dir2/dir1/file5.txt":  &fstest.MapFile{},
+		"dir2/subdir/file6.go": &fstest.MapFile{},
+	}
+	temp := t.TempDir()
+	be.NilErr(t, os.CopyFS(temp, testFS))
+
+	for _, tr := range []walker.Ranger{
+		walker.New(testFS, ".", walker.OnErrorHalt),
+		walker.New(nil, temp, walker.OnErrorHalt),
+	} {
+		tr.Include(walker.MatchRegexpRelMust(`^dir1/.*\.txt$`))
+		var paths []string
+		for e := range tr.FileEntries() {
+			paths = append(paths, filepath.ToSlash(e.RelRoot()))
+		}
+		be.NilErr(t, tr.Err())
+		be.Equal(t, "dir1/file3.txt", strings.Join(paths, "; "))
+	}
+
+	tr := walker.New(testFS, "dir2", walker.OnErrorHalt)
+	tr.Include(walker.MatchRegexpRelMust(`^dir1/`))
+	be.Equal(t, "dir2/dir1/file5.txt", strings.Join(slices.Collect(tr.FilePaths()), "; "))
+}
+
+func TestMatchPathLongerThan(t *testing.T) {
+	deep := strings.Repeat("nested/", 40) + "file.txt"
+	testFS := fstest.MapFS{
+		"a.txt": &fstest.MapFile{},
+		deep:    &fstest.MapFile{},
+	}
+	tr := walker.New(testFS, ".", walker.OnErrorHalt)
+	tr.Include(walker.MatchWindowsLongPath)
+	paths := slices.Collect(tr.FilePaths())
+	be.NilErr(t, tr.Err())
+	be.Equal(t, deep, strings.Join(paths, "; "))
+
+	for _, tc := range []struct {
+		n    int
+		want bool
+	}{
+		{4, true},
+		{5, false},
+		{6, false},
+	} {
+		be.Equal(t, tc.want, walker.MatchPathLongerThan(tc.n)(walker.Entry{Path: "a.txt"}))
+	}
+}
+
+func TestMatchHidden(t *testing.T) {
+	testFS := fstest.MapFS{
+		".env":           &fstest.MapFile{},
+		".git/config":    &fstest.MapFile{},
+		"a.txt":          &fstest.MapFile{},
+		"dir1/.hidden":   &fstest.MapFile{},
+		"dir1/file3.txt": &fstest.MapFile{},
+	}
+	tr := walker.New(testFS, ".", walker.OnErrorHalt)
+	tr.Include(walker.MatchHidden)
+	paths := slices.Collect(tr.Paths())
+	be.NilErr(t, tr.Err())
+	be.Equal(t, ".env; .git; dir1/.hidden", strings.Join(paths, "; "))
+}
+
+func TestMatchSample(t *testing.T) {
+	testFS := fstest.MapFS{}
+	for i := range 100 {
+		testFS[fmt.Sprintf("dir%d/file%02d.txt", i%3, i)] = &fstest.MapFile{}
+	}
+	sample := func() []string {
+		tr := walker.New(testFS, ".", walker.OnErrorHalt)
+		tr.Include(walker.MatchSample(10))
+		paths := slices.Collect(tr.FilePaths())
+		be.NilErr(t, tr.Err())
+		return paths
+	}
+	paths := sample()
+	be.Equal(t, 10, len(paths))
+	be.Equal(t, "dir0/file27.txt", paths[0])
+	be.Equal(t, strings.Join(paths, "; "), strings.Join(sample(), "; "))
+
+	tr := walker.New(testFS, ".", walker.OnErrorHalt)
+	tr.Include(walker.MatchSample(1))
+	be.Equal(t, 100, len(slices.Collect(tr.FilePaths())))
+}
+
+func TestFilterEntries(t *testing.T) {
+	testFS := fstest.MapFS{
+		"a.txt":                &fstest.MapFile{},
+		"dir1/file3.txt":       &fstest.MapFile{},
+		"dir1/file4.log":       &fstest.MapFile{},
+		"dir2/file5.txt":       &fstest.MapFile{},
+		"dir2/subdir/file6.go": &fstest.MapFile{},
+	}
+	tr := walker.New(testFS, ".", walker.OnErrorHalt)
+	entries, err := tr.Collect()
+	be.NilErr(t, err)
+
+	seq := walker.FilterEntries(slices.Values(entries), walker.MatchExtension(".txt"))
+	seq = walker.FilterEntries(seq, walker.MatchGlobPath("dir*/*"))
+	var paths []string
+	for e := range seq {
+		paths = append(paths, e.Path)
+	}
+	be.Equal(t, "dir1/file3.txt; dir2/file5.txt", strings.Join(paths, "; "))
+
+	paths = nil
+	for e := range walker.FilterEntries(tr.FileEntries(), walker.MatchExtension(".go", ".log")) {
+		paths = append(paths, e.Path)
+		break
+	}
+	be.Equal(t, "dir1/file4.log", strings.Join(paths, "; "))
+}
+
+func TestMatchGlobBrace(t *testing.T) {
+	testFS := fstest.MapFS{}
+	for _, name := range strings.Fields(
+		"a a.gif a.jpg a.log a.md a.png a.txt ab abb b b.log b.txt c.txt " +
+			"img.jp img.jpeeg img.jpeg img.jpg img.png x{y.txt xy.txt {a}.txt {}",
+	) {
+		testFS["dir/"+name] = &fstest.MapFile{}
+	}
+	for _, tc := range []struct {
+		pattern string
+		want    string
+	}{
+		{"*.{jpg,png}", "a.jpg a.png img.jpg img.png"},
+		{"{a,b}.{txt,log}", "a.log a.txt b.log b.txt"},
+		{"img.{jp{,e}g,png}", "img.jpeg img.jpg img.png"},
+		{"a{,b}", "a ab"},
+		{"{}", "{}"},
+		{"{a}.txt", "{a}.txt"},
+		{"x{y.txt", "x{y.txt"},
+	} {
+		tr := walker.New(testFS, "dir", walker.OnErrorHalt)
+		tr.Include(walker.MatchGlobBrace(tc.pattern))
+		var names []string
+		for e := range tr.FileEntries() {
+			names = append(names, e.Name())
+		}
+		be.NilErr(t, tr.Err())
+		be.Equal(t, tc.want, strings.Join(names, " "))
+	}
+}
+
+func TestMatchNameEqualsParent(t *testing.T) {
+	for _, tc := range []struct {
+		path           string
+		strip, noStrip bool
+	}{
+		{"foo/foo.go", true, false},
+		{"foo/bar.go", false, false},
+		{"dir/foo/foo", true, true},
+		{"foo/foo.test.go", false, false},
+		{"foo.go", false, false},
+	} {
+		e := walker.Entry{Path: tc.path}
+		be.Equal(t, tc.strip, walker.MatchNameEqualsParent(true)(e))
+		be.Equal(t, tc.noStrip, walker.MatchNameEqualsParent(false)(e))
+	}
+}
+
+func TestMatchInList(t *testing.T) {
+	testFS := fstest.MapFS{
+		"src/a.txt":          {},
+		"src/dir1/file3.txt": {},
+		"src/dir1/file4.txt": {},
+		"src/file1.txt":      {},
+	}
+	list := []string{"a.txt", "./dir1/file4.txt", "dir1/../file1.txt", "missing.txt"}
+	tr := walker.New(testFS, "src", walker.OnErrorHalt)
+	tr.Include(walker.MatchInList(list))
+	paths := slices.Collect(tr.FilePaths())
+	be.NilErr(t, tr.Err())
+	be.Equal(t, "src/a.txt; src/dir1/file4.txt; src/file1.txt", strings.Join(paths, "; "))
+
+	tr.Include(walker.MatchNotInList(list))
+	paths = slices.Collect(tr.FilePaths())
+	be.Equal(t, "src/dir1/file3.txt", strings.Join(paths, "; "))
+}
+
+func TestMatchInfo(t *testing.T) {
+	testFS := fstest.MapFS{
+		"big.sh":     {Data: make([]byte, 100), Mode: 0o755},
+		"big.txt":    {Data: make([]byte, 100), Mode: 0o644},
+		"small.sh":   {Data: make([]byte, 10), Mode: 0o755},
+		"dir/big.sh": {Data: make([]byte, 200), Mode: 0o700},
+	}
+	tr := walker.New(testFS, ".", walker.OnErrorHalt)
+	tr.Include(walker.MatchInfo(func(info fs.FileInfo) bool {
+		return info.Size() >= 100 && info.Mode()&0o100 != 0
+	}))
+	paths := slices.Collect(tr.FilePaths())
+	be.NilErr(t, tr.Err())
+	be.Equal(t, "big.sh; dir/big.sh", strings.Join(paths, "; "))
+
+	be.False(t, walker.MatchInfo(func(fs.FileInfo) bool { return true })(walker.Entry{}))
+}
+
+func TestMatchMode(t *testing.T) {
+	testFS := fstest.MapFS{
+		"a.txt":       {Mode: 0o644},
+		"dir/b.txt":   {Mode: 0o600},
+		"dir/link":    {Data: []byte("b.txt"), Mode: fs.ModeSymlink | 0o777},
+		"fifo":        {Mode: fs.ModeNamedPipe | 0o644},
+		"null":        {Mode: fs.ModeDevice | fs.ModeCharDevice | 0o666},
+		"sock":        {Mode: fs.ModeSocket | 0o755},
+		"subdir/link": {Data: []byte("../a.txt"), Mode: fs.ModeSymlink},
+	}
+	for _, tc := range []struct {
+		name   string
+		filter walker.FilterFunc
+		want   string
+	}{
+		{"regular", walker.MatchRegular, "a.txt; dir/b.txt"},
+		{"symlink", walker.MatchSymlink, "dir/link; subdir/link"},
+		{"named pipe", walker.MatchNamedPipe, "fifo"},
+		{"char device", walker.MatchMode(fs.ModeCharDevice, fs.ModeCharDevice), "null"},
+		{"not regular", walker.Not(walker.MatchRegular),
+			"dir/link; fifo; null; sock; subdir/link"},
+		{"socket", walker.MatchMode(fs.ModeSocket|fs.ModeNamedPipe|fs.ModeSymlink, fs.ModeSocket),
+			"sock"},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			tr := walker.New(testFS, ".", walker.OnErrorHalt)
+			tr.Include(tc.filter)
+			paths := slices.Collect(tr.FilePaths())
+			be.NilErr(t, tr.Err())
+			be.Equal(t, tc.want, strings.Join(paths, "; "))
+		})
+	}
+
+	be.False(t, walker.MatchRegular(walker.Entry{}))
+}
+
+func TestMatchGlobPathInsensitive(t *testing.T) {
+	testFS := fstest.MapFS{
+		"Docs/README.md":        {},
+		"docs/guide/Intro.MD":   {},
+		"DOCS/Guide/setup.md":   {},
+		"src/Docs/notes.md":     {},
+		"Docs/images/logo.png":  {},
+		"other/readme.markdown": {},
+	}
+	tr := walker.New(testFS, ".", walker.OnErrorHalt)
+	tr.Include(walker.MatchGlobPathInsensitive("docs/*.md", "DOCS/GUIDE/*.Md"))
+	paths := slices.Collect(tr.FilePaths())
+	be.NilErr(t, tr.Err())
+	be.Equal(t, "DOCS/Guide/setup.md; Docs/README.md; docs/guide/Intro.MD", strings.Join(paths, "; "))
+
+	tr.Include(walker.MatchGlobPath("docs/*.md"))
+	paths = slices.Collect(tr.FilePaths())
+	be.Equal(t, 0, len(paths))
+}
@@ -0,0 +1,269 @@
+//go:build unix && !aix && !solaris
+
+package walker_test
+
+import (
+	"io/fs"
+	"net"
+	"os"
+	"os/user"
+	"path/filepath"
+	"slices"
+	"strings"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/carlmjohnson/be"
+	"github.com/earthboundkid/walker"
+)
+
+func TestMatchNamedPipe(t *testing.T) {
+	dir := t.TempDir()
+	be.NilErr(t, os.WriteFile(filepath.Join(dir, "file.txt"), nil, 0o644))
+	be.NilErr(t, syscall.Mkfifo(filepath.Join(dir, "pipe"), 0o644))
+
+	for _, tc := range []struct {
+		name   string
+		filter walker.FilterFunc
+		want   string
+	}{
+		{"pipe", walker.MatchNamedPipe, "pipe"},
+		{"socket", walker.MatchSocket, ""},
+		{"device", walker.MatchDevice, ""},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			tr := walker.New(nil, dir, walker.OnErrorHalt)
+			tr.Include(tc.filter)
+			var names []string
+			for path := range tr.FilePaths() {
+				names = append(names, filepath.Base(path))
+			}
+			be.NilErr(t, tr.Err())
+			be.Equal(t, tc.want, strings.Join(names, "; "))
+		})
+	}
+
+	tr := walker.New(nil, dir, walker.OnErrorHalt)
+	tr.Exclude(walker.MatchNamedPipe)
+	paths := slices.Collect(tr.FilePaths())
+	be.Equal(t, 1, len(paths))
+	be.Equal(t, "file.txt", filepath.Base(paths[0]))
+}
+
+func TestEntry_ModeType(t *testing.T) {
+	dir := t.TempDir()
+	be.NilErr(t, os.WriteFile(filepath.Join(dir, "file.txt"), nil, 0o644))
+	be.NilErr(t, os.Mkdir(filepath.Join(dir, "subdir"), 0o755))
+	be.NilErr(t, os.Symlink("file.txt", filepath.Join(dir, "link")))
+	be.NilErr(t, syscall.Mkfifo(filepath.Join(dir, "pipe"), 0o644))
+	if l, err := net.Listen("unix", filepath.Join(dir, "sock")); err == nil {
+		defer l.Close()
+	} else {
+		t.Logf("no socket: %v", err)
+	}
+
+	kind := func(e walker.Entry) string {
+		var kinds []string
+		for _, k := range []struct {
+			name string
+			is   bool
+		}{
+			{"regular", e.IsRegular()},
+			{"dir", e.IsDir()},
+			{"symlink", e.IsSymlink()},
+			{"pipe", e.IsNamedPipe()},
+			{"socket", e.IsSocket()},
+			{"device", e.IsDevice()},
+		} {
+			if k.is {
+				kinds = append(kinds, k.name)
+			}
+		}
+		return strings.Join(kinds, ",")
+	}
+	want := map[string]string{
+		"file.txt": "regular",
+		"subdir":   "dir",
+		"link":     "symlink",
+		"pipe":     "pipe",
+		"sock":     "socket",
+	}
+	tr := walker.New(nil, dir, walker.OnErrorHalt)
+	for e := range tr.Entries() {
+		if e.Path == dir {
+			continue
+		}
+		be.Equal(t, want[e.Name()], kind(e))
+		be.Equal(t, e.DirEntry.Type().Type(), e.ModeType())
+	}
+	be.NilErr(t, tr.Err())
+
+	info, err := os.Lstat("/dev/null")
+	be.NilErr(t, err)
+	e := walker.Entry{Path: "/dev/null", DirEntry: fs.FileInfoToDirEntry(info)}
+	be.Equal(t, "device", kind(e))
+
+	var zero walker.Entry
+	be.Equal(t, "", kind(zero))
+	be.Equal(t, fs.FileMode(0), zero.ModeType())
+}
+
+func TestMatchOwnedBy(t *testing.T) {
+	dir := t.TempDir()
+	be.NilErr(t, os.WriteFile(filepath.Join(dir, "file.txt"), nil, 0o644))
+
+	tr := walker.New(nil, dir, walker.OnErrorHalt)
+	tr.Include(walker.MatchOwnedByUID(os.Getuid()))
+	be.Equal(t, 1, len(slices.Collect(tr.FilePaths())))
+
+	tr.Include(walker.MatchOwnedByUID(os.Getuid() + 1))
+	be.Equal(t, 0, len(slices.Collect(tr.FilePaths())))
+
+	u, err := user.Current()
+	be.NilErr(t, err)
+	tr.Include(walker.MatchOwnedByName(u.Username))
+	be.Equal(t, 1, len(slices.Collect(tr.FilePaths())))
+
+	tr.Include(walker.MatchOwnedByName("no-such-user-walker-test"))
+	be.Equal(t, 0, len(slices.Collect(tr.FilePaths())))
+}
+
+func TestMatchInvalidUTF8Name(t *testing.T) {
+	dir := t.TempDir()
+	be.NilErr(t, os.WriteFile(filepath.Join(dir, "good.txt"), nil, 0o644))
+	bad := "bad-\xff\xfe.txt"
+	if err := os.WriteFile(filepath.Join(dir, bad), nil, 0o644); err != nil {
+		t.Skip("filesystem rejects invalid UTF-8 names:", err)
+	}
+
+	tr := walker.New(nil, dir, walker.OnErrorHalt)
+	tr.Include(walker.MatchInvalidUTF8Name)
+	paths := slices.Collect(tr.FilePaths())
+	be.NilErr(t, tr.Err())
+	be.Equal(t, 1, len(paths))
+	be.Equal(t, bad, filepath.Base(paths[0]))
+
+	tr.Include(walker.MatchValidUTF8Name)
+	paths = slices.Collect(tr.FilePaths())
+	be.Equal(t, 1, len(paths))
+	be.Equal(t, "good.txt", filepath.Base(paths[0]))
+}
+
+func TestMatchAccessedBefore(t *testing.T) {
+	dir := t.TempDir()
+	now := time.Now()
+	old := now.Add(-30 * 24 * time.Hour)
+	for name, atime := range map[string]time.Time{
+		"old.txt": old,
+		"new.txt": now,
+	} {
+		path := filepath.Join(dir, name)
+		be.NilErr(t, os.WriteFile(path, nil, 0o644))
+		be.NilErr(t, os.Chtimes(path, atime, now))
+	}
+
+	cutoff := now.Add(-24 * time.Hour)
+	tr := walker.New(nil, dir, walker.OnErrorHalt)
+	tr.Include(walker.MatchAccessedBefore(cutoff))
+	paths := slices.Collect(tr.FilePaths())
+	be.NilErr(t, tr.Err())
+	be.Equal(t, 1, len(paths))
+	be.Equal(t, "old.txt", filepath.Base(paths[0]))
+
+	tr.Include(walker.MatchAccessedAfter(cutoff))
+	paths = slices.Collect(tr.FilePaths())
+	be.Equal(t, 1, len(paths))
+	be.Equal(t, "new.txt", filepath.Base(paths[0]))
+}
+
+func TestMatchNotInUse(t *testing.T) {
+	dir := t.TempDir()
+	be.NilErr(t, os.WriteFile(filepath.Join(dir, "free.txt"), nil, 0o644))
+	be.NilErr(t, os.WriteFile(filepath.Join(dir, "locked.txt"), nil, 0o644))
+	f, err := os.Open(filepath.Join(dir, "locked.txt"))
+	be.NilErr(t, err)
+	defer f.Close()
+	be.NilErr(t, syscall.Flock(int(f.Fd()), syscall.LOCK_EX))
+
+	tr := walker.New(nil, dir, walker.OnErrorHalt)
+	tr.Include(walker.MatchNotInUse())
+	var names []string
+	for path := range tr.FilePaths() {
+		names = append(names, filepath.Base(path))
+	}
+	be.NilErr(t, tr.Err())
+	be.Equal(t, "free.txt", strings.Join(names, "; "))
+
+	be.NilErr(t, syscall.Flock(int(f.Fd()), syscall.LOCK_UN))
+	be.Equal(t, 2, len(slices.Collect(tr.FilePaths())))
+}
+
+func TestMatchPermExact(t *testing.T) {
+	dir := t.TempDir()
+	for name, perm := range map[string]os.FileMode{
+		"open.sh":   0o777,
+		"normal.sh": 0o644,
+		"exec.sh":   0o755,
+	} {
+		name = filepath.Join(dir, name)
+		be.NilErr(t, os.WriteFile(name, nil, 0o600))
+		be.NilErr(t, os.Chmod(name, perm))
+	}
+
+	tr := walker.New(nil, dir, walker.OnErrorHalt)
+	tr.Include(walker.MatchPermExact(0o777))
+	var names []string
+	for path := range tr.FilePaths() {
+		names = append(names, filepath.Base(path))
+	}
+	be.NilErr(t, tr.Err())
+	be.Equal(t, "open.sh", strings.Join(names, "; "))
+}
+
+func TestMatchReadable(t *testing.T) {
+	if os.Geteuid() == 0 {
+		t.Skip("root can read any file")
+	}
+	dir := t.TempDir()
+	be.NilErr(t, os.WriteFile(filepath.Join(dir, "readable.txt"), nil, 0o644))
+	be.NilErr(t, os.WriteFile(filepath.Join(dir, "secret.txt"), nil, 0o000))
+
+	var errs []error
+	tr := walker.New(nil, dir, walker.OnErrorCollect(&errs))
+	tr.Include(walker.MatchReadable())
+	var names []string
+	for path := range tr.FilePaths() {
+		names = append(names, filepath.Base(path))
+	}
+	be.NilErr(t, tr.Err())
+	be.Equal(t, 0, len(errs))
+	be.Equal(t, "readable.txt", strings.Join(names, "; "))
+}
+
+func TestMatchLinkCount(t *testing.T) {
+	dir := t.TempDir()
+	be.NilErr(t, os.WriteFile(filepath.Join(dir, "single.txt"), nil, 0o644))
+	be.NilErr(t, os.WriteFile(filepath.Join(dir, "shared.txt"), nil, 0o644))
+	be.NilErr(t, os.Link(filepath.Join(dir, "shared.txt"), filepath.Join(dir, "shared-link.txt")))
+
+	tr := walker.New(nil, dir, walker.OnErrorHalt)
+	tr.Include(walker.MatchLinkCount(2, -1))
+	var names []string
+	for path := range tr.FilePaths() {
+		names = append(names, filepath.Base(path))
+	}
+	be.NilErr(t, tr.Err())
+	be.Equal(t, "shared-link.txt; shared.txt", strings.Join(names, "; "))
+
+	tr.Include(walker.MatchLinkCount(-1, 1))
+	names = nil
+	for path := range tr.FilePaths() {
+		names = append(names, filepath.Base(path))
+	}
+	be.Equal(t, "single.txt", strings.Join(names, "; "))
+
+	fsTR := walker.New(os.DirFS(dir), ".", walker.OnErrorHalt)
+	fsTR.Include(walker.MatchLinkCount(2, 2))
+	be.Equal(t, 2, len(slices.Collect(fsTR.FilePaths())))
+}
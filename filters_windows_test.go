@@ -0,0 +1,50 @@
+package walker_test
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+	"testing/fstest"
+
+	"github.com/carlmjohnson/be"
+	"github.com/earthboundkid/walker"
+)
+
+func TestSlashPathFilters(t *testing.T) {
+	testFS := fstest.MapFS{
+		"a.txt":                &fstest.MapFile{},
+		"dir1/file3.txt":       &fstest.MapFile{},
+		"dir2/subdir/file6.go": &fstest.MapFile{},
+	}
+	temp := t.TempDir()
+	be.NilErr(t, os.CopyFS(temp, testFS))
+	slashTemp := filepath.ToSlash(temp)
+
+	for _, tc := range []struct {
+		name   string
+		filter walker.FilterFunc
+		want   string
+	}{
+		{"regexp", walker.MatchRegexpMust(`dir2/subdir/`), "file6.go"},
+		{"glob", walker.MatchGlobPath(slashTemp + "/dir1/*"), "file3.txt"},
+		{"prefix", walker.MatchPrefixPath(slashTemp + "/dir2/sub"), "file6.go"},
+		{"rel glob", walker.MatchGlobRel("dir1/*.txt"), "file3.txt"},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			tr := walker.New(nil, temp, walker.OnErrorHalt)
+			tr.Include(tc.filter)
+			var names []string
+			for path := range tr.FilePaths() {
+				names = append(names, filepath.Base(path))
+			}
+			be.NilErr(t, tr.Err())
+			be.Equal(t, tc.want, strings.Join(names, "; "))
+		})
+	}
+
+	tr := walker.New(testFS, ".", walker.OnErrorHalt)
+	tr.Include(walker.MatchRegexpMust(`^dir2/subdir/`))
+	be.Equal(t, "dir2/subdir/file6.go", strings.Join(slices.Collect(tr.FilePaths()), "; "))
+}
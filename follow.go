@@ -0,0 +1,193 @@
+package walker
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+)
+
+// ErrSymlinkCycle is passed to the ErrorPolicy
+// when FollowSymlinks is set and a symbolic link
+// leads to a directory which contains it,
+// so following it would walk the same directories forever.
+var ErrSymlinkCycle = errors.New("walker: symbolic link cycle")
+
+// readLinkFS is the method set of fs.ReadLinkFS from newer versions of Go.
+type readLinkFS interface {
+	fs.FS
+	ReadLink(name string) (string, error)
+	Lstat(name string) (fs.FileInfo, error)
+}
+
+// FollowSymlinks tells the Ranger whether to follow symbolic links.
+// When set, a link to a directory is yielded as a directory
+// and its contents are walked under the link's path,
+// while a link to a file is yielded as a file.
+// Either way, Entry.IsSymlink still reports true,
+// and Info describes the target of the link.
+// Broken links are yielded as they are.
+// A link to a directory which contains the link,
+// directly or through other links,
+// is not descended into;
+// instead, an error wrapping ErrSymlinkCycle
+// is passed to the ErrorPolicy with the link's Entry.
+//
+// Links are followed when walking the OS filesystem.
+// An fs.FS is only followed if it has the methods of fs.ReadLinkFS,
+// ReadLink(name string) (string, error) and Lstat(name string) (fs.FileInfo, error),
+// as os.DirFS does in newer versions of Go,
+// and only links to relative paths within the fs.FS can be followed.
+// Otherwise, as with a NewFunc Ranger, FollowSymlinks has no effect.
+// NoFollowDirs takes precedence over FollowSymlinks.
+// The default is false.
+func (tr *Ranger) FollowSymlinks(follow bool) {
+	tr.followLinks = follow
+}
+
+// canFollow reports whether the Ranger follows symbolic links.
+func (tr *Ranger) canFollow() bool {
+	if !tr.followLinks || tr.readDir != nil {
+		return false
+	}
+	if tr.fsys == nil {
+		return true
+	}
+	_, ok := tr.fsys.(readLinkFS)
+	return ok
+}
+
+// followLink returns d, or if d is a symbolic link which the Ranger follows,
+// a DirEntry for the link which reports the type and info of its target.
+func (tr *Ranger) followLink(name string, d fs.DirEntry) fs.DirEntry {
+	if d.Type()&fs.ModeSymlink == 0 || !tr.canFollow() {
+		return d
+	}
+	var info fs.FileInfo
+	var err error
+	if tr.fsys == nil {
+		info, err = os.Stat(name)
+	} else {
+		info, err = fs.Stat(tr.fsys, name)
+	}
+	if err != nil {
+		return d
+	}
+	return linkDirEntry{d, info}
+}
+
+// linkDirEntry is the DirEntry of a followed symbolic link.
+type linkDirEntry struct {
+	link   fs.DirEntry
+	target fs.FileInfo
+}
+
+func (d linkDirEntry) Name() string               { return d.link.Name() }
+func (d linkDirEntry) IsDir() bool                { return d.target.IsDir() }
+func (d linkDirEntry) Type() fs.FileMode          { return d.target.Mode().Type() | fs.ModeSymlink }
+func (d linkDirEntry) Info() (fs.FileInfo, error) { return d.target, nil }
+
+// enterLink checks whether descending into the followed link to a directory name
+// would cycle back to a directory already being walked.
+// If not, it records the directory containing the link
+// and returns a function to call once the link's contents have been walked.
+func (tr *Ranger) enterLink(name string) (leave func(), err error) {
+	target, err := tr.realPath(name)
+	if err != nil {
+		return nil, err
+	}
+	parent, err := tr.realPath(tr.parentDir(name))
+	if err != nil {
+		return nil, err
+	}
+	// The walk is inside parent and inside the parent of each link it followed.
+	// If target contains any of them, walking it would reach the link again.
+	n := len(tr.linkParents)
+	tr.linkParents = append(tr.linkParents, parent)
+	leave = func() {
+		tr.linkParents = tr.linkParents[:n]
+	}
+	for _, dir := range tr.linkParents {
+		if tr.isWithinDir(dir, target) {
+			leave()
+			return nil, &fs.PathError{Op: "walk", Path: name, Err: ErrSymlinkCycle}
+		}
+	}
+	return leave, nil
+}
+
+// parentDir returns the directory containing name, using the Ranger's path separator.
+func (tr *Ranger) parentDir(name string) string {
+	if tr.useFilepath() {
+		return filepath.Dir(name)
+	}
+	return path.Dir(name)
+}
+
+// isWithinDir reports whether name is dir or inside it.
+// Both must be cleaned paths as returned by realPath.
+func (tr *Ranger) isWithinDir(name, dir string) bool {
+	sep := "/"
+	if tr.useFilepath() {
+		sep = string(filepath.Separator)
+	} else if dir == "." {
+		return true
+	}
+	return name == dir || strings.HasPrefix(name, strings.TrimSuffix(dir, sep)+sep)
+}
+
+// realPath returns the path of name with every symbolic link resolved.
+func (tr *Ranger) realPath(name string) (string, error) {
+	if tr.fsys == nil {
+		resolved, err := filepath.EvalSymlinks(name)
+		if err != nil {
+			return "", err
+		}
+		return filepath.Abs(resolved)
+	}
+	return resolveLinks(tr.fsys.(readLinkFS), name)
+}
+
+// resolveLinks is like filepath.EvalSymlinks for a readLinkFS.
+// Links to absolute paths cannot be resolved,
+// and links to paths above the root of fsys resolve to the root.
+func resolveLinks(fsys readLinkFS, name string) (string, error) {
+	resolved := "."
+	rest := strings.Split(name, "/")
+	for hops := 0; len(rest) > 0; {
+		elem := rest[0]
+		rest = rest[1:]
+		switch elem {
+		case "", ".":
+			continue
+		case "..":
+			resolved = path.Dir(resolved)
+			continue
+		}
+		next := path.Join(resolved, elem)
+		info, err := fsys.Lstat(next)
+		if err != nil {
+			return "", err
+		}
+		if info.Mode()&fs.ModeSymlink == 0 {
+			resolved = next
+			continue
+		}
+		if hops++; hops > 255 {
+			return "", &fs.PathError{Op: "readlink", Path: name, Err: errors.New("too many links")}
+		}
+		target, err := fsys.ReadLink(next)
+		if err != nil {
+			return "", err
+		}
+		if path.IsAbs(target) || filepath.IsAbs(target) {
+			return "", &fs.PathError{Op: "readlink", Path: next,
+				Err: fmt.Errorf("absolute link target %q: %w", target, fs.ErrInvalid)}
+		}
+		rest = append(strings.Split(target, "/"), rest...)
+	}
+	return resolved, nil
+}
@@ -0,0 +1,97 @@
+package walker_test
+
+import (
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/carlmjohnson/be"
+	"github.com/earthboundkid/walker"
+)
+
+// linkFS is an os.DirFS with the methods of fs.ReadLinkFS.
+type linkFS struct {
+	fs.FS
+	dir string
+}
+
+func (fsys linkFS) ReadLink(name string) (string, error) {
+	target, err := os.Readlink(filepath.Join(fsys.dir, name))
+	return filepath.ToSlash(target), err
+}
+
+func (fsys linkFS) Lstat(name string) (fs.FileInfo, error) {
+	return os.Lstat(filepath.Join(fsys.dir, name))
+}
+
+func TestRanger_FollowSymlinks(t *testing.T) {
+	dir := t.TempDir()
+	for _, name := range []string{"albums/real", "library", "a", "b"} {
+		be.NilErr(t, os.MkdirAll(filepath.Join(dir, name), 0o755))
+	}
+	be.NilErr(t, os.WriteFile(filepath.Join(dir, "albums/real/song.mp3"), nil, 0o644))
+	for link, target := range map[string]string{
+		"library/album":     "../albums/real",
+		"library/track.mp3": "../albums/real/song.mp3",
+		"library/broken":    "../missing",
+		"library/loop":      "..",
+		"a/to-b":            "../b",
+		"b/to-a":            "../a",
+	} {
+		be.NilErr(t, os.Symlink(target, filepath.Join(dir, link)))
+	}
+
+	for _, tc := range []struct {
+		name string
+		fsys fs.FS
+		root string
+	}{
+		{"os", nil, dir},
+		{"fs", linkFS{os.DirFS(dir), dir}, "."},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			var errs []error
+			tr := walker.New(tc.fsys, tc.root, walker.OnErrorCollect(&errs))
+			rel := func(seq func(func(walker.Entry) bool)) string {
+				var paths []string
+				for e := range seq {
+					paths = append(paths, filepath.ToSlash(e.RelRoot()))
+				}
+				return strings.Join(paths, "; ")
+			}
+			be.Equal(t, "a/to-b; albums/real/song.mp3; b/to-a; "+
+				"library/album; library/broken; library/loop; library/track.mp3",
+				rel(tr.FileEntries()))
+			be.Equal(t, 0, len(errs))
+
+			tr.FollowSymlinks(true)
+			be.Equal(t, "albums/real/song.mp3; "+
+				"library/album/song.mp3; library/broken; library/track.mp3",
+				rel(tr.FileEntries()))
+			be.Equal(t, 3, len(errs))
+			for _, err := range errs {
+				be.True(t, errors.Is(err, walker.ErrSymlinkCycle))
+			}
+
+			errs = nil
+			tr.BreadthFirst(true)
+			be.Equal(t, "library/broken; library/track.mp3; "+
+				"albums/real/song.mp3; library/album/song.mp3",
+				rel(tr.FileEntries()))
+			be.Equal(t, 3, len(errs))
+			tr.BreadthFirst(false)
+
+			for e := range tr.FileEntries() {
+				if e.Name() == "track.mp3" {
+					be.True(t, e.IsSymlink())
+					info, err := e.DirEntry.Info()
+					be.NilErr(t, err)
+					be.True(t, info.Mode().IsRegular())
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,129 @@
+package walker
+
+import (
+	"bytes"
+	"fmt"
+	"io/fs"
+	"os"
+	"os/exec"
+	"path"
+	"path/filepath"
+	"strings"
+)
+
+// MatchChangedSince returns a FilterFunc that matches files
+// that differ between the git revision rev and the working tree of repo,
+// as reported by "git diff --name-only".
+// The changed paths are read once, when MatchChangedSince is called,
+// and compared against Entry.RelRoot, so the Ranger should be rooted at repo.
+// Untracked files are not considered changed.
+// A rev beginning with "-" would be parsed by git as an option,
+// so it is rejected with an error wrapping fs.ErrInvalid.
+//
+// MatchChangedSince runs the git executable,
+// which must be installed and on the PATH.
+func MatchChangedSince(repo, rev string) (FilterFunc, error) {
+	if strings.HasPrefix(rev, "-") {
+		return nil, fmt.Errorf("walker: invalid git revision %q: %w", rev, fs.ErrInvalid)
+	}
+	cmd := exec.Command("git", "-C", repo, "diff", "--name-only", "--relative", "-z", rev, "--")
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	out, err := cmd.Output()
+	if err != nil {
+		return nil, fmt.Errorf("walker: git diff %q: %w: %s",
+			rev, err, bytes.TrimSpace(stderr.Bytes()))
+	}
+	changed := make(map[string]bool)
+	for _, name := range bytes.Split(out, []byte{0}) {
+		if len(name) > 0 {
+			changed[string(name)] = true
+		}
+	}
+	return func(e Entry) bool {
+		return changed[filepath.ToSlash(e.RelRoot())]
+	}, nil
+}
+
+// MatchGitRelPath returns a FilterFunc that matches entries
+// whose path relative to the root of their enclosing git repository
+// matches the slash separated glob pattern,
+// which uses the syntax of path.Match.
+// The repository root is the nearest ancestor directory containing a ".git" entry,
+// which may lie above the root of the walk when walking the OS filesystem.
+// For an fs.FS, the search stops at the root of the filesystem.
+// Entries outside any repository,
+// entries from a NewFunc Ranger,
+// and malformed patterns do not match.
+// The repository root found for each directory is cached
+// for the life of the FilterFunc.
+// It does not run the git executable.
+func MatchGitRelPath(pattern string) FilterFunc {
+	cache := make(map[string]string)
+	var repoRoot func(e Entry, dir string) string
+	repoRoot = func(e Entry, dir string) string {
+		if root, ok := cache[dir]; ok {
+			return root
+		}
+		var root string
+		var parent string
+		if e.useFilepath {
+			parent = filepath.Dir(dir)
+		} else {
+			parent = path.Dir(dir)
+		}
+		switch {
+		case hasGitDir(e, dir):
+			root = dir
+		case parent != dir:
+			root = repoRoot(e, parent)
+		}
+		cache[dir] = root
+		return root
+	}
+	return func(e Entry) bool {
+		var name, dir string
+		switch {
+		case e.useFilepath:
+			abs, err := filepath.Abs(e.Path)
+			if err != nil {
+				return false
+			}
+			name, dir = abs, filepath.Dir(abs)
+		case e.fsys != nil:
+			name, dir = e.Path, path.Dir(e.Path)
+		default:
+			return false
+		}
+		root := repoRoot(e, dir)
+		if root == "" {
+			return false
+		}
+		var rel string
+		if e.useFilepath {
+			r, err := filepath.Rel(root, name)
+			if err != nil {
+				return false
+			}
+			rel = filepath.ToSlash(r)
+		} else if root == "." {
+			rel = name
+		} else {
+			rel = name[len(root)+1:]
+		}
+		matched, err := path.Match(pattern, rel)
+		return err == nil && matched
+	}
+}
+
+// hasGitDir reports whether dir contains a ".git" entry,
+// which is a directory in a repository and a file in a worktree or submodule.
+func hasGitDir(e Entry, dir string) bool {
+	var err error
+	if e.useFilepath {
+		_, err = os.Lstat(filepath.Join(dir, ".git"))
+	} else {
+		_, err = fs.Stat(e.fsys, path.Join(dir, ".git"))
+	}
+	return err == nil
+}
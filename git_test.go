@@ -0,0 +1,112 @@
+package walker_test
+
+import (
+	"errors"
+	"io/fs"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+	"testing/fstest"
+
+	"github.com/carlmjohnson/be"
+	"github.com/earthboundkid/walker"
+)
+
+func TestMatchChangedSince(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not installed")
+	}
+	repo := t.TempDir()
+	git := func(args ...string) {
+		t.Helper()
+		cmd := exec.Command("git", append([]string{
+			"-C", repo,
+			"-c", "user.name=test",
+			"-c", "user.email=test@example.com",
+			"-c", "commit.gpgsign=false",
+		}, args...)...)
+		out, err := cmd.CombinedOutput()
+		if err != nil {
+			t.Fatalf("git %v: %v\n%s", args, err, out)
+		}
+	}
+	write := func(name, data string) {
+		t.Helper()
+		name = filepath.Join(repo, name)
+		be.NilErr(t, os.MkdirAll(filepath.Dir(name), 0o755))
+		be.NilErr(t, os.WriteFile(name, []byte(data), 0o644))
+	}
+	git("init", "-q")
+	write("a.txt", "a")
+	write("dir1/file3.txt", "b")
+	git("add", ".")
+	git("commit", "-q", "-m", "initial")
+	write("dir1/file3.txt", "changed")
+	write("untracked.txt", "c")
+
+	match, err := walker.MatchChangedSince(repo, "HEAD")
+	be.NilErr(t, err)
+	tr := walker.New(nil, repo, walker.OnErrorHalt)
+	tr.ExcludeDir(walker.MatchGlobName(".git"))
+	tr.Include(match)
+	var paths []string
+	for e := range tr.FileEntries() {
+		paths = append(paths, filepath.ToSlash(e.RelRoot()))
+	}
+	be.NilErr(t, tr.Err())
+	be.Equal(t, "dir1/file3.txt", strings.Join(paths, "; "))
+
+	_, err = walker.MatchChangedSince(repo, "no-such-rev")
+	be.Nonzero(t, err)
+
+	out := filepath.Join(t.TempDir(), "out")
+	_, err = walker.MatchChangedSince(repo, "--output="+out)
+	be.True(t, errors.Is(err, fs.ErrInvalid))
+	_, err = os.Stat(out)
+	be.True(t, errors.Is(err, fs.ErrNotExist))
+}
+
+func TestMatchGitRelPath(t *testing.T) {
+	testFS := fstest.MapFS{
+		"outside/pkg/a.go":           &fstest.MapFile{},
+		"mono/.git/HEAD":             &fstest.MapFile{},
+		"mono/main.go":               &fstest.MapFile{},
+		"mono/pkg/b.go":              &fstest.MapFile{},
+		"mono/pkg/deep/c.go":         &fstest.MapFile{},
+		"mono/nested/.git":           &fstest.MapFile{Data: []byte("gitdir: ../.git/modules/nested\n")},
+		"mono/nested/pkg/d.go":       &fstest.MapFile{},
+		"mono/nested/other/pkg/e.go": &fstest.MapFile{},
+	}
+	const want = "mono/nested/pkg/d.go; mono/pkg/b.go"
+
+	tr := walker.New(testFS, ".", walker.OnErrorHalt)
+	tr.Include(walker.MatchGitRelPath("pkg/*.go"))
+	paths := slices.Collect(tr.FilePaths())
+	be.NilErr(t, tr.Err())
+	be.Equal(t, want, strings.Join(paths, "; "))
+
+	temp := t.TempDir()
+	be.NilErr(t, os.CopyFS(temp, testFS))
+	rel := func(paths []string) string {
+		for i := range paths {
+			paths[i], _ = filepath.Rel(temp, paths[i])
+			paths[i] = filepath.ToSlash(paths[i])
+		}
+		return strings.Join(paths, "; ")
+	}
+	tr = walker.New(nil, temp, walker.OnErrorHalt)
+	tr.Include(walker.MatchGitRelPath("pkg/*.go"))
+	paths = slices.Collect(tr.FilePaths())
+	be.NilErr(t, tr.Err())
+	be.Equal(t, want, rel(paths))
+
+	// The repository root may be above the walk root.
+	tr = walker.New(nil, filepath.Join(temp, "mono", "pkg"), walker.OnErrorHalt)
+	tr.Include(walker.MatchGitRelPath("pkg/*/*.go"))
+	paths = slices.Collect(tr.FilePaths())
+	be.NilErr(t, tr.Err())
+	be.Equal(t, "mono/pkg/deep/c.go", rel(paths))
+}
@@ -3,3 +3,5 @@ module github.com/earthboundkid/walker
 go 1.23.2
 
 require github.com/carlmjohnson/be v0.23.2
+
+require golang.org/x/sys v0.35.0
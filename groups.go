@@ -0,0 +1,84 @@
+package walker
+
+import (
+	"path"
+	"slices"
+)
+
+// SourceCodeExtensions are the file extensions matched by MatchSourceCode.
+// Append to it before calling MatchSourceCode to match additional extensions.
+var SourceCodeExtensions = []string{
+	".c", ".cc", ".cpp", ".cs", ".css", ".go", ".h", ".hpp", ".html",
+	".java", ".js", ".jsx", ".kt", ".lua", ".m", ".php", ".pl", ".py",
+	".rb", ".rs", ".scala", ".sh", ".sql", ".swift", ".ts", ".tsx", ".zig",
+}
+
+// ImageExtensions are the file extensions matched by MatchImage.
+// Append to it before calling MatchImage to match additional extensions.
+var ImageExtensions = []string{
+	".apng", ".avif", ".bmp", ".gif", ".heic", ".heif", ".ico", ".jfif", ".jp2",
+	".jpeg", ".jpg", ".jxl", ".png", ".svg", ".tga", ".tif", ".tiff", ".webp",
+}
+
+// RawImageExtensions are the file extensions matched by MatchRawImage.
+// Append to it before calling MatchRawImage to match additional extensions.
+var RawImageExtensions = []string{
+	".3fr", ".arw", ".cr2", ".cr3", ".crw", ".dcr", ".dng", ".erf", ".kdc", ".mef",
+	".mos", ".mrw", ".nef", ".nrw", ".orf", ".pef", ".raf", ".raw", ".rw2", ".rwl",
+	".sr2", ".srf", ".srw", ".x3f",
+}
+
+// ArchiveExtensions are the file extensions matched by MatchArchive.
+// Append to it before calling MatchArchive to match additional extensions.
+var ArchiveExtensions = []string{
+	".7z", ".bz2", ".gz", ".rar", ".tar", ".tgz", ".xz", ".zip", ".zst",
+}
+
+// EditorJunkPatterns are the glob patterns matched by MatchEditorJunk,
+// covering editor backup, lock, and swap files
+// and operating system metadata files.
+// Append to it before calling MatchEditorJunk to match additional patterns.
+var EditorJunkPatterns = []string{
+	"*~", ".#*", "#*#", "*.swp", ".DS_Store", "Thumbs.db",
+}
+
+// MatchSourceCode returns a FilterFunc that matches files with
+// any of the SourceCodeExtensions.
+func MatchSourceCode() FilterFunc {
+	return MatchExtension(slices.Clone(SourceCodeExtensions)...)
+}
+
+// MatchImage returns a FilterFunc that matches files with
+// any of the ImageExtensions.
+func MatchImage() FilterFunc {
+	return MatchExtension(slices.Clone(ImageExtensions)...)
+}
+
+// MatchRawImage returns a FilterFunc that matches camera raw image files with
+// any of the RawImageExtensions.
+// Combine it with MatchImage using Or to match all image files.
+func MatchRawImage() FilterFunc {
+	return MatchExtension(slices.Clone(RawImageExtensions)...)
+}
+
+// MatchArchive returns a FilterFunc that matches files with
+// any of the ArchiveExtensions.
+func MatchArchive() FilterFunc {
+	return MatchExtension(slices.Clone(ArchiveExtensions)...)
+}
+
+// MatchEditorJunk returns a FilterFunc that matches files
+// whose Entry.Base() matches any of the EditorJunkPatterns,
+// using the syntax of path.Match.
+func MatchEditorJunk() FilterFunc {
+	patterns := slices.Clone(EditorJunkPatterns)
+	return func(e Entry) bool {
+		name := e.Base()
+		for _, pattern := range patterns {
+			if matched, err := path.Match(pattern, name); err == nil && matched {
+				return true
+			}
+		}
+		return false
+	}
+}
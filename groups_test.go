@@ -0,0 +1,72 @@
+package walker_test
+
+import (
+	"testing"
+
+	"github.com/carlmjohnson/be"
+	"github.com/earthboundkid/walker"
+)
+
+func TestExtensionGroups(t *testing.T) {
+	source, image, archive := walker.MatchSourceCode(), walker.MatchImage(), walker.MatchArchive()
+	for _, tc := range []struct {
+		path                   string
+		source, image, archive bool
+	}{
+		{"main.go", true, false, false},
+		{"script.py", true, false, false},
+		{"lib.rs", true, false, false},
+		{"photo.png", false, true, false},
+		{"PHOTO.JPG", false, true, false},
+		{"backup.zip", false, false, true},
+		{"notes.txt", false, false, false},
+	} {
+		e := walker.Entry{Path: tc.path}
+		be.Equal(t, tc.source, source(e))
+		be.Equal(t, tc.image, image(e))
+		be.Equal(t, tc.archive, archive(e))
+	}
+}
+
+func TestMatchRawImage(t *testing.T) {
+	raw, image := walker.MatchRawImage(), walker.MatchImage()
+	for _, tc := range []struct {
+		path       string
+		raw, image bool
+	}{
+		{"IMG_0001.CR2", true, false},
+		{"DSC_0001.nef", true, false},
+		{"DSC00001.ARW", true, false},
+		{"photo.dng", true, false},
+		{"photo.heic", false, true},
+		{"photo.avif", false, true},
+		{"photo.jpg", false, true},
+		{"notes.txt", false, false},
+	} {
+		e := walker.Entry{Path: tc.path}
+		be.Equal(t, tc.raw, raw(e))
+		be.Equal(t, tc.image, image(e))
+	}
+}
+
+func TestMatchEditorJunk(t *testing.T) {
+	junk := walker.MatchEditorJunk()
+	for _, tc := range []struct {
+		path string
+		want bool
+	}{
+		{"notes.txt~", true},
+		{"dir/.#notes.txt", true},
+		{"#notes.txt#", true},
+		{"dir/.notes.txt.swp", true},
+		{".DS_Store", true},
+		{"photos/Thumbs.db", true},
+		{"notes.txt", false},
+		{"dir~/notes.txt", false},
+		{"#channel", false},
+		{"swp", false},
+		{"DS_Store", false},
+	} {
+		be.Equal(t, tc.want, junk(walker.Entry{Path: tc.path}))
+	}
+}
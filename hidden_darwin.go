@@ -0,0 +1,10 @@
+package walker
+
+// ufHidden is the UF_HIDDEN file flag from sys/stat.h.
+const ufHidden = 0x8000
+
+// hasHiddenAttr reports whether e has the UF_HIDDEN flag set.
+func hasHiddenAttr(e Entry) bool {
+	st, ok := statT(e)
+	return ok && st.Flags&ufHidden != 0
+}
@@ -0,0 +1,9 @@
+//go:build !darwin && !windows
+
+package walker
+
+// hasHiddenAttr reports whether e is hidden by a file attribute.
+// This platform has no such attribute, so it always returns false.
+func hasHiddenAttr(e Entry) bool {
+	return false
+}
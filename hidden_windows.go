@@ -0,0 +1,16 @@
+package walker
+
+import "syscall"
+
+// hasHiddenAttr reports whether e has the hidden file attribute set.
+func hasHiddenAttr(e Entry) bool {
+	if e.DirEntry == nil {
+		return false
+	}
+	info, err := e.DirEntry.Info()
+	if err != nil {
+		return false
+	}
+	attrs, ok := info.Sys().(*syscall.Win32FileAttributeData)
+	return ok && attrs.FileAttributes&syscall.FILE_ATTRIBUTE_HIDDEN != 0
+}
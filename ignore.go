@@ -0,0 +1,158 @@
+package walker
+
+import (
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+)
+
+// ignoreRule is a single line of a gitignore style file.
+type ignoreRule struct {
+	pattern  string
+	negate   bool
+	dirOnly  bool
+	anchored bool
+}
+
+// parseIgnoreRules parses the lines of a gitignore style file.
+// Blank lines and lines beginning with # are skipped.
+func parseIgnoreRules(data string) []ignoreRule {
+	var rules []ignoreRule
+	for _, line := range strings.Split(data, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		var r ignoreRule
+		line, r.negate = strings.CutPrefix(line, "!")
+		line, r.dirOnly = strings.CutSuffix(line, "/")
+		line = strings.TrimPrefix(line, `\`)
+		// A slash anywhere but the end anchors the pattern to the root.
+		r.anchored = strings.Contains(line, "/")
+		r.pattern = strings.TrimPrefix(line, "/")
+		if r.pattern != "" {
+			rules = append(rules, r)
+		}
+	}
+	return rules
+}
+
+// match reports whether the rule matches the slash separated path rel.
+func (r ignoreRule) match(rel string, isDir bool) bool {
+	if r.dirOnly && !isDir {
+		return false
+	}
+	if !r.anchored {
+		matched, err := path.Match(r.pattern, path.Base(rel))
+		return err == nil && matched
+	}
+	return matchDoubleStar(strings.Split(r.pattern, "/"), strings.Split(rel, "/"))
+}
+
+// matchDoubleStar reports whether the path elements segs match
+// the pattern elements, using path.Match for each element.
+// As in gitignore, an element of ** matches zero or more path elements,
+// except at the end of the pattern, where it matches one or more.
+func matchDoubleStar(pattern, segs []string) bool {
+	for len(pattern) > 0 {
+		if pattern[0] == "**" {
+			if len(pattern) == 1 {
+				return len(segs) > 0
+			}
+			for i := range len(segs) + 1 {
+				if matchDoubleStar(pattern[1:], segs[i:]) {
+					return true
+				}
+			}
+			return false
+		}
+		if len(segs) == 0 {
+			return false
+		}
+		if matched, err := path.Match(pattern[0], segs[0]); err != nil || !matched {
+			return false
+		}
+		pattern, segs = pattern[1:], segs[1:]
+	}
+	return len(segs) == 0
+}
+
+// ignored reports whether rel is ignored by the rules.
+// As with gitignore, the last matching rule wins,
+// so a negated rule can re-include a path ignored by an earlier rule.
+func ignored(rules []ignoreRule, rel string, isDir bool) bool {
+	ignore := false
+	for _, r := range rules {
+		if r.match(rel, isDir) {
+			ignore = !r.negate
+		}
+	}
+	return ignore
+}
+
+// LoadIgnoreFile reads the named ignore file from fsys,
+// or from the OS filesystem if fsys is nil,
+// and adds its rules to the Ranger with AddExclude and AddExcludeDir.
+// The file uses a subset of gitignore syntax:
+// blank lines and lines beginning with # are skipped,
+// a leading ! negates a pattern, re-including paths excluded by an earlier pattern,
+// a trailing / matches only directories,
+// and a pattern containing a / is matched against the path relative to the walk root,
+// while other patterns are matched against the name.
+// Patterns use the syntax of path.Match,
+// plus ** as a path element matching any number of directories.
+// As with gitignore, files in an excluded directory
+// cannot be re-included because the directory is not walked.
+func (tr *Ranger) LoadIgnoreFile(fsys fs.FS, name string) error {
+	var data []byte
+	var err error
+	if fsys == nil {
+		data, err = os.ReadFile(name)
+	} else {
+		data, err = fs.ReadFile(fsys, name)
+	}
+	if err != nil {
+		return err
+	}
+	rules := parseIgnoreRules(string(data))
+	match := func(e Entry) bool {
+		rel := filepath.ToSlash(e.RelRoot())
+		return rel != "." && ignored(rules, rel, e.IsDir())
+	}
+	tr.AddExclude(match)
+	tr.AddExcludeDir(match)
+	return nil
+}
+
+// MatchGitignore returns a FilterFunc that matches entries
+// ignored by the given gitignore style patterns,
+// in the syntax used by LoadIgnoreFile, one pattern per argument:
+// a leading / anchors a pattern to the walk root,
+// a trailing / matches only directories,
+// ** matches any number of directories,
+// as in "**/build", "logs/**", or "a/**/b",
+// and a leading ! negates a pattern.
+// Patterns are evaluated in order and the last one to match wins,
+// so a negated pattern re-includes paths matched by an earlier pattern.
+// Paths are matched relative to the walk root,
+// so the filter works the same for an fs.FS and the OS filesystem.
+// As with gitignore, an entry inside an ignored directory is also matched,
+// and cannot be re-included by a negated pattern,
+// so the filter can be used with Exclude alone.
+func MatchGitignore(patterns ...string) FilterFunc {
+	rules := parseIgnoreRules(strings.Join(patterns, "\n"))
+	return func(e Entry) bool {
+		rel := filepath.ToSlash(e.RelRoot())
+		if rel == "." {
+			return false
+		}
+		for i := range len(rel) {
+			if rel[i] == '/' && ignored(rules, rel[:i], true) {
+				return true
+			}
+		}
+		return ignored(rules, rel, e.IsDir())
+	}
+}
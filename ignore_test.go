@@ -0,0 +1,99 @@
+package walker_test
+
+import (
+	"errors"
+	"io/fs"
+	"slices"
+	"strings"
+	"testing"
+	"testing/fstest"
+
+	"github.com/carlmjohnson/be"
+	"github.com/earthboundkid/walker"
+)
+
+func TestRanger_LoadIgnoreFile(t *testing.T) {
+	testFS := fstest.MapFS{
+		".walkerignore": &fstest.MapFile{Data: []byte(`
+# Logs are noisy
+*.log
+!important.log
+
+build/
+/top.txt
+dir2/subdir
+`)},
+		"a.txt":                &fstest.MapFile{},
+		"top.txt":              &fstest.MapFile{},
+		"debug.log":            &fstest.MapFile{},
+		"important.log":        &fstest.MapFile{},
+		"build/out.txt":        &fstest.MapFile{},
+		"dir1/build":           &fstest.MapFile{},
+		"dir1/top.txt":         &fstest.MapFile{},
+		"dir1/file4.log":       &fstest.MapFile{},
+		"dir1/important.log":   &fstest.MapFile{},
+		"dir2/file5.txt":       &fstest.MapFile{},
+		"dir2/subdir/file6.go": &fstest.MapFile{},
+	}
+	tr := walker.New(testFS, ".", walker.OnErrorHalt)
+	be.NilErr(t, tr.LoadIgnoreFile(testFS, ".walkerignore"))
+	paths := slices.Collect(tr.FilePaths())
+	be.NilErr(t, tr.Err())
+	be.Equal(t, ".walkerignore; a.txt; dir1/build; dir1/important.log; dir1/top.txt; "+
+		"dir2/file5.txt; important.log", strings.Join(paths, "; "))
+
+	err := tr.LoadIgnoreFile(testFS, "missing")
+	be.True(t, errors.Is(err, fs.ErrNotExist))
+}
+
+func TestMatchGitignore(t *testing.T) {
+	testFS := fstest.MapFS{
+		"a.txt":                  &fstest.MapFile{},
+		"top.txt":                &fstest.MapFile{},
+		"debug.log":              &fstest.MapFile{},
+		"important.log":          &fstest.MapFile{},
+		"build/out.txt":          &fstest.MapFile{},
+		"dir1/build":             &fstest.MapFile{},
+		"dir1/top.txt":           &fstest.MapFile{},
+		"dir1/tmp/x.txt":         &fstest.MapFile{},
+		"dir2/file5.txt":         &fstest.MapFile{},
+		"dir2/subdir/file6.go":   &fstest.MapFile{},
+		"docs/a/b/manual.pdf":    &fstest.MapFile{},
+		"docs/manual.pdf":        &fstest.MapFile{},
+		"docs/readme.md":         &fstest.MapFile{},
+		"logs/2024/01/today.txt": &fstest.MapFile{},
+	}
+	for _, tc := range []struct {
+		name     string
+		patterns []string
+		want     string
+	}{
+		{"unanchored", []string{"top.txt"},
+			"a.txt; build/out.txt; debug.log; dir1/build; dir1/tmp/x.txt; dir2/file5.txt; " +
+				"dir2/subdir/file6.go; docs/a/b/manual.pdf; docs/manual.pdf; docs/readme.md; " +
+				"important.log; logs/2024/01/today.txt"},
+		{"anchored", []string{"/top.txt", "/dir2/subdir", "/docs/readme.md", "/logs"},
+			"a.txt; build/out.txt; debug.log; dir1/build; dir1/tmp/x.txt; dir1/top.txt; " +
+				"dir2/file5.txt; docs/a/b/manual.pdf; docs/manual.pdf; important.log"},
+		{"dir only", []string{"build/", "dir*/", "docs/", "logs/"},
+			"a.txt; debug.log; important.log; top.txt"},
+		{"negate", []string{"*.log", "!important.log", "*.txt", "!/dir1/**"},
+			"dir1/build; dir1/tmp/x.txt; dir1/top.txt; dir2/subdir/file6.go; " +
+				"docs/a/b/manual.pdf; docs/manual.pdf; docs/readme.md; important.log"},
+		{"negate in ignored dir", []string{"dir1/", "!dir1/top.txt"},
+			"a.txt; build/out.txt; debug.log; dir2/file5.txt; dir2/subdir/file6.go; " +
+				"docs/a/b/manual.pdf; docs/manual.pdf; docs/readme.md; important.log; " +
+				"logs/2024/01/today.txt; top.txt"},
+		{"double star", []string{"**/tmp", "docs/**/*.pdf", "logs/**", "**/subdir/**"},
+			"a.txt; build/out.txt; debug.log; dir1/build; dir1/top.txt; dir2/file5.txt; " +
+				"docs/readme.md; important.log; top.txt"},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			tr := walker.New(testFS, ".", walker.OnErrorHalt)
+			tr.Exclude(walker.MatchGitignore(tc.patterns...))
+			paths := slices.Collect(tr.FilePaths())
+			be.NilErr(t, tr.Err())
+			be.Equal(t, tc.want, strings.Join(paths, "; "))
+		})
+	}
+}
@@ -0,0 +1,75 @@
+package walker
+
+import (
+	"io/fs"
+	"sync"
+	"time"
+)
+
+// modTime returns the modification time of e, if available.
+func modTime(e Entry) (time.Time, bool) {
+	if e.DirEntry == nil {
+		return time.Time{}, false
+	}
+	info, err := e.DirEntry.Info()
+	if err != nil {
+		return time.Time{}, false
+	}
+	return info.ModTime(), true
+}
+
+// size returns the size of e, if available.
+func size(e Entry) (int64, bool) {
+	if e.DirEntry == nil {
+		return 0, false
+	}
+	info, err := e.DirEntry.Info()
+	if err != nil {
+		return 0, false
+	}
+	return info.Size(), true
+}
+
+// infoCache wraps a DirEntry so that Info is only called once,
+// however many filters ask for it.
+// Entries are copied by value, so it is shared by pointer.
+type infoCache struct {
+	fs.DirEntry
+	once sync.Once
+	info fs.FileInfo
+	err  error
+}
+
+// cacheInfo wraps d in an infoCache, unless it is nil or already wrapped.
+func cacheInfo(d fs.DirEntry) fs.DirEntry {
+	switch d.(type) {
+	case nil, *infoCache:
+		return d
+	}
+	return &infoCache{DirEntry: d}
+}
+
+func (c *infoCache) Info() (fs.FileInfo, error) {
+	c.once.Do(func() {
+		c.info, c.err = c.DirEntry.Info()
+	})
+	return c.info, c.err
+}
+
+// RequireInfo returns an ErrFilterFunc that reads the info of each entry
+// and reports an error if it cannot be read,
+// or otherwise calls f.
+// Filters such as MatchMinSize and MatchModifiedAfter
+// do not match entries whose info cannot be read;
+// wrap them with RequireInfo and pass them to Ranger.IncludeErr or Ranger.ExcludeErr
+// to pass the failures to the ErrorPolicy instead.
+// The info is cached for each entry during a walk,
+// so it is only read once however many filters use it.
+func RequireInfo(f FilterFunc) ErrFilterFunc {
+	return func(e Entry) (bool, error) {
+		if _, err := e.Info(); err != nil {
+			return false, err
+		}
+		return f(e), nil
+	}
+}
@@ -0,0 +1,41 @@
+//go:build unix && !aix && !solaris
+
+package walker
+
+import (
+	"errors"
+	"os"
+	"syscall"
+)
+
+// MatchNotInUse returns a FilterFunc that matches files
+// which are not locked by another process.
+// It opens each file and attempts a non-blocking flock,
+// so it only detects processes that hold advisory flock locks;
+// a process that merely has the file open is not detected.
+// The check is best effort: the file may be locked
+// as soon as the filter returns.
+// Directories, files that cannot be opened,
+// and entries from an fs.FS are treated as not in use.
+// On platforms without flock, it always matches.
+func MatchNotInUse() FilterFunc {
+	return func(e Entry) bool {
+		if e.IsDir() || !e.useFilepath {
+			return true
+		}
+		f, err := os.Open(e.Path)
+		if err != nil {
+			return true
+		}
+		defer f.Close()
+		fd := int(f.Fd())
+		err = syscall.Flock(fd, syscall.LOCK_EX|syscall.LOCK_NB)
+		if errors.Is(err, syscall.EWOULDBLOCK) {
+			return false
+		}
+		if err == nil {
+			_ = syscall.Flock(fd, syscall.LOCK_UN)
+		}
+		return true
+	}
+}
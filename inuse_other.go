@@ -0,0 +1,12 @@
+//go:build !unix || aix || solaris
+
+package walker
+
+// MatchNotInUse returns a FilterFunc that matches files
+// which are not locked by another process.
+// Lock detection relies on flock,
+// which is not supported on this platform,
+// so it always matches.
+func MatchNotInUse() FilterFunc {
+	return func(Entry) bool { return true }
+}
@@ -0,0 +1,11 @@
+//go:build !unix
+
+package walker
+
+// MatchLinkCount returns a FilterFunc that matches entries
+// whose number of hard links is between min and max inclusive.
+// Reading the link count is only supported on Unix,
+// so on this platform it never matches.
+func MatchLinkCount(min, max int) FilterFunc {
+	return func(Entry) bool { return false }
+}
@@ -0,0 +1,16 @@
+//go:build unix
+
+package walker
+
+// MatchLinkCount returns a FilterFunc that matches entries
+// whose number of hard links is between min and max inclusive.
+// Pass -1 for min or max to leave that end unbounded.
+// It calls Info() and reads the link count from the underlying syscall.Stat_t,
+// so it only works for filesystems which expose one, such as the OS filesystem.
+// On other platforms and filesystems, it never matches.
+func MatchLinkCount(min, max int) FilterFunc {
+	return func(e Entry) bool {
+		st, ok := statT(e)
+		return ok && inRange(int(st.Nlink), min, max)
+	}
+}
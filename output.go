@@ -0,0 +1,61 @@
+package walker
+
+import (
+	"cmp"
+	"encoding/json"
+	"fmt"
+	"hash"
+	"io"
+	"path/filepath"
+	"slices"
+)
+
+// WriteJSONL writes each matching entry to w as a line of JSON.
+// Each line is written as soon as its entry is walked.
+// It returns the first write error or else the Ranger's Err().
+func (tr *Ranger) WriteJSONL(w io.Writer) error {
+	enc := json.NewEncoder(w)
+	for e := range tr.Entries() {
+		if err := enc.Encode(e); err != nil {
+			return err
+		}
+	}
+	return tr.Err()
+}
+
+// Manifest hashes each matching file, ignoring directories,
+// with a new hash.Hash from h, such as sha256.New,
+// and writes a line to w for each file in the format of sha256sum:
+// the hex encoded hash, two spaces, and the slash separated path relative to the root.
+// Lines are sorted by path, so the manifest does not depend on walk order,
+// and nothing is written until the walk is done.
+// Failures to read a file are passed to the ErrorPolicy,
+// and if it halts, Manifest stops and returns the failure.
+// It returns the first write error or else the Ranger's Err().
+func (tr *Ranger) Manifest(h func() hash.Hash, w io.Writer) error {
+	type line struct{ sum, path string }
+	var lines []line
+	for e := range tr.FileEntries() {
+		sum, err := hashFile(e, h())
+		if err != nil {
+			if !tr.handleError(err, e) {
+				tr.lastErr, tr.errEntry = err, e
+				return err
+			}
+			continue
+		}
+		lines = append(lines, line{sum, filepath.ToSlash(e.RelRoot())})
+	}
+	if err := tr.Err(); err != nil {
+		return err
+	}
+	slices.SortFunc(lines, func(a, b line) int {
+		return cmp.Compare(a.path, b.path)
+	})
+	for _, l := range lines {
+		if _, err := fmt.Fprintf(w, "%s  %s\n", l.sum, l.path); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,101 @@
+package walker_test
+
+import (
+	"bufio"
+	"crypto/sha256"
+	"encoding/json"
+	"io/fs"
+	"strings"
+	"testing"
+	"testing/fstest"
+
+	"github.com/carlmjohnson/be"
+	"github.com/earthboundkid/walker"
+)
+
+func TestRanger_WriteJSONL(t *testing.T) {
+	testFS := fstest.MapFS{
+		"a.txt":          &fstest.MapFile{},
+		"dir1/file3.txt": &fstest.MapFile{},
+		"dir1/file4.log": &fstest.MapFile{},
+	}
+	tr := walker.New(testFS, ".", walker.OnErrorHalt)
+	tr.Exclude(walker.MatchExtension(".log"))
+	var buf strings.Builder
+	be.NilErr(t, tr.WriteJSONL(&buf))
+
+	type line struct {
+		Path  string `json:"path"`
+		Name  string `json:"name"`
+		IsDir bool   `json:"is_dir"`
+	}
+	var got []line
+	s := bufio.NewScanner(strings.NewReader(buf.String()))
+	for s.Scan() {
+		var l line
+		be.NilErr(t, json.Unmarshal(s.Bytes(), &l))
+		got = append(got, l)
+	}
+	be.NilErr(t, s.Err())
+	be.AllEqual(t, []line{
+		{".", ".", true},
+		{"a.txt", "a.txt", false},
+		{"dir1", "dir1", true},
+		{"dir1/file3.txt", "file3.txt", false},
+	}, got)
+}
+
+func TestRanger_Manifest(t *testing.T) {
+	testFS := fstest.MapFS{
+		"a.txt":          {Data: []byte("a\n")},
+		"a/file3.txt":    {Data: []byte("b\n")},
+		"a-b/file4.txt":  {Data: []byte("c\n")},
+		"dir1/file5.log": {Data: []byte("d\n")},
+		"empty.txt":      {},
+	}
+	tr := walker.New(testFS, ".", walker.OnErrorHalt)
+	tr.Exclude(walker.MatchExtension(".log"))
+	var buf strings.Builder
+	be.NilErr(t, tr.Manifest(sha256.New, &buf))
+	// Golden output from sha256sum.
+	be.Equal(t, ""+
+		"a3a5e715f0cc574a73c3f9bebb6bc24f32ffd5b67b387244c2c909da779a1478  a-b/file4.txt\n"+
+		"87428fc522803d31065e7bce3cf03fe475096631e5e07bbd7a0fde60c4cf25c7  a.txt\n"+
+		"0263829989b6fd954f72baaf2fc64bc2e2f01d692d4de72986ea808f6e99813f  a/file3.txt\n"+
+		"e3b0c44298fc1c149afbf4c8996fb92427ae41e4649b934ca495991b7852b855  empty.txt\n",
+		buf.String())
+
+	// Entries from a NewFunc Ranger cannot be opened.
+	calls := 0
+	readDir := func(string) ([]fs.DirEntry, error) {
+		return []fs.DirEntry{infoDirEntry{"a.txt", &calls}}, nil
+	}
+	tr = walker.NewFunc(readDir, "root", walker.OnErrorHalt)
+	err := tr.Manifest(sha256.New, &buf)
+	be.Nonzero(t, err)
+	be.Equal(t, err, tr.Err())
+	be.Equal(t, "root/a.txt", tr.ErrPath())
+
+	// A failure on the last file does not discard the manifest
+	// if the policy continues.
+	tr = walker.New(badOpenFS{testFS, "z.txt"}, ".", walker.OnErrorIgnore)
+	testFS["z.txt"] = &fstest.MapFile{}
+	buf.Reset()
+	be.NilErr(t, tr.Manifest(sha256.New, &buf))
+	be.NilErr(t, tr.Err())
+	be.Equal(t, 5, strings.Count(buf.String(), "\n"))
+	be.False(t, strings.Contains(buf.String(), "z.txt"))
+}
+
+// badOpenFS is an fs.FS which fails to open the file named bad.
+type badOpenFS struct {
+	fstest.MapFS
+	bad string
+}
+
+func (fsys badOpenFS) Open(name string) (fs.File, error) {
+	if name == fsys.bad {
+		return nil, &fs.PathError{Op: "open", Path: name, Err: fs.ErrPermission}
+	}
+	return fsys.MapFS.Open(name)
+}
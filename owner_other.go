@@ -0,0 +1,10 @@
+//go:build !unix
+
+package walker
+
+// MatchOwnedByUID returns a FilterFunc that matches entries owned by the user ID uid.
+// File ownership by user ID is only supported on Unix,
+// so on this platform it never matches.
+func MatchOwnedByUID(uid int) FilterFunc {
+	return func(Entry) bool { return false }
+}
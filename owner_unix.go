@@ -0,0 +1,29 @@
+//go:build unix
+
+package walker
+
+import "syscall"
+
+// MatchOwnedByUID returns a FilterFunc that matches entries owned by the user ID uid.
+// It calls Info() and reads the owner from the underlying syscall.Stat_t,
+// so it only works for filesystems which expose one, such as the OS filesystem.
+// On other platforms and filesystems, it never matches.
+func MatchOwnedByUID(uid int) FilterFunc {
+	return func(e Entry) bool {
+		st, ok := statT(e)
+		return ok && int64(st.Uid) == int64(uid)
+	}
+}
+
+// statT returns the syscall.Stat_t for e, if available.
+func statT(e Entry) (*syscall.Stat_t, bool) {
+	if e.DirEntry == nil {
+		return nil, false
+	}
+	info, err := e.DirEntry.Info()
+	if err != nil {
+		return nil, false
+	}
+	st, ok := info.Sys().(*syscall.Stat_t)
+	return st, ok
+}
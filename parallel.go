@@ -0,0 +1,200 @@
+package walker
+
+import (
+	"io/fs"
+	"iter"
+	"sync"
+)
+
+// SetConcurrency sets the number of goroutines ParallelEntries uses to read directories.
+// A value less than 2 makes ParallelEntries walk serially like Entries.
+// The default is 0.
+func (tr *Ranger) SetConcurrency(n int) {
+	tr.concurrency = n
+}
+
+// dirListing is the result of reading a directory in ParallelEntries.
+type dirListing struct {
+	dir     Entry
+	entries []fs.DirEntry
+	err     error
+}
+
+// ParallelEntries returns a sequence of Entries for matching files and directories
+// like Entries, but reads directories using the number of goroutines
+// set by SetConcurrency, which speeds up walking slow filesystems.
+// The order of the entries is nondeterministic,
+// except that a directory is yielded before its contents.
+//
+// Only reading directories happens in other goroutines.
+// The filters, ErrorPolicy, Trace, and OnSkipDir hook are applied
+// and entries are yielded in the iterating goroutine,
+// so they need not be safe for concurrent use,
+// and SkipDir works as it does with Entries.
+// A directory's contents may already have been read when SkipDir is called,
+// but they are not yielded.
+// When the loop stops early or the ErrorPolicy halts the walk,
+// the goroutines stop before the sequence returns.
+//
+// Options which depend on the order of the walk,
+// such as Range, the cursor of NewResume, SortBy, SortedByModTime,
+// SampleDirs, LimitPerDir, and SizeBudget, are ignored,
+// as are FollowSymlinks, MaxVisits, DedupPaths, ProgressFraction,
+// and the OnEnterDir and OnLeaveDir hooks.
+// If readDir is provided with NewFunc, it must be safe for concurrent use.
+func (tr *Ranger) ParallelEntries() iter.Seq[Entry] {
+	return func(yield func(Entry) bool) {
+		if tr.concurrency < 2 {
+			for e := range tr.Entries() {
+				if !yield(e) {
+					return
+				}
+			}
+			return
+		}
+		if tr.omitDirs {
+			yieldFile := yield
+			yield = func(e Entry) bool {
+				return e.IsDir() || yieldFile(e)
+			}
+		}
+		template, ok := tr.startWalk()
+		if !ok {
+			return
+		}
+		defer func() { tr.isWalking = false }()
+
+		jobs := make(chan Entry)
+		listings := make(chan dirListing)
+		done := make(chan struct{})
+		var wg sync.WaitGroup
+		for range tr.concurrency {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				for dir := range jobs {
+					entries, err := tr.readDirNamed(dir.Path)
+					select {
+					case listings <- dirListing{dir, entries, err}:
+					case <-done:
+						return
+					}
+				}
+			}()
+		}
+		defer func() {
+			close(done)
+			close(jobs)
+			wg.Wait()
+		}()
+
+		// queue holds the directories waiting to be read,
+		// and pending counts them along with the ones being read.
+		var queue []Entry
+		pending := 0
+		// visitEntry filters and yields e,
+		// queues it to be read if it is a directory,
+		// and reports whether to continue.
+		// If SkipDir is called for a file, skipDir is left set
+		// so the rest of its directory can be skipped.
+		visitEntry := func(e Entry) bool {
+			if e.Dir() == tr.root || e.IsDir() {
+				if reason := tr.rejectDir(e); reason != "" {
+					if e.Dir() != tr.root {
+						tr.skipDirFor(e, reason)
+						tr.skipDir = false
+						return true
+					}
+					// As with Entries, the root's files are excluded
+					// but the root itself is still read.
+					tr.trace("exclude", e, reason)
+					if e.IsDir() {
+						queue = append(queue, e)
+						pending++
+					}
+					return true
+				}
+			}
+			reason := tr.rejectFile(e)
+			if reason == "" {
+				var err error
+				reason, err = tr.rejectFileErr(e)
+				if err != nil {
+					tr.lastErr, tr.errEntry = err, e
+					if !tr.handleError(err, e) {
+						tr.trace("halt", e, err.Error())
+						return false
+					}
+					tr.trace("ignore", e, err.Error())
+				}
+			}
+			tr.skipDir = false
+			if reason != "" {
+				tr.trace("exclude", e, reason)
+			} else {
+				tr.trace("include", e, "")
+				tr.lastYielded = e.Path
+				if !yield(tr.absolute(e)) {
+					return false
+				}
+			}
+			if e.IsDir() {
+				if !tr.skipDir {
+					queue = append(queue, e)
+					pending++
+				}
+				tr.skipDir = false
+			}
+			return true
+		}
+
+		d, err := tr.statRoot()
+		root := template
+		root.Path, root.DirEntry = tr.root, cacheInfo(d)
+		if err != nil {
+			tr.lastErr, tr.errEntry = err, root
+			if !tr.handleError(err, root) {
+				tr.trace("halt", root, err.Error())
+			}
+			return
+		}
+		if !visitEntry(root) {
+			return
+		}
+		for pending > 0 {
+			var next Entry
+			var send chan<- Entry
+			if len(queue) > 0 {
+				next, send = queue[0], jobs
+			}
+			select {
+			case send <- next:
+				queue = queue[1:]
+			case l := <-listings:
+				pending--
+				if l.err != nil {
+					tr.lastErr, tr.errEntry = l.err, l.dir
+					if !tr.handleError(l.err, l.dir) {
+						tr.trace("halt", l.dir, l.err.Error())
+						return
+					}
+					tr.trace("ignore", l.dir, l.err.Error())
+				}
+				if tr.stableOrder {
+					l.entries = sortedByName(l.entries)
+				}
+				for _, d := range l.entries {
+					e := template
+					e.Path, e.DirEntry = tr.join(l.dir.Path, d.Name()), cacheInfo(d)
+					if !visitEntry(e) {
+						return
+					}
+					if tr.skipDir {
+						tr.skipDir = false
+						break
+					}
+				}
+			}
+		}
+	}
+}
@@ -0,0 +1,134 @@
+package walker_test
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"runtime"
+	"slices"
+	"strings"
+	"testing"
+	"testing/fstest"
+	"time"
+
+	"github.com/carlmjohnson/be"
+	"github.com/earthboundkid/walker"
+)
+
+func TestRanger_ParallelEntries(t *testing.T) {
+	testFS := fstest.MapFS{
+		"a.txt":                &fstest.MapFile{},
+		"dir1/file3.txt":       &fstest.MapFile{},
+		"dir1/file4.log":       &fstest.MapFile{},
+		"dir2/file5.txt":       &fstest.MapFile{},
+		"dir2/subdir/file6.go": &fstest.MapFile{},
+		"dir3/sub/file7.txt":   &fstest.MapFile{},
+		"file1.txt":            &fstest.MapFile{},
+		"file2.log":            &fstest.MapFile{},
+		"node_modules/x.txt":   &fstest.MapFile{},
+	}
+	tr := walker.New(testFS, ".", walker.OnErrorHalt)
+	tr.SetConcurrency(4)
+	tr.Include(walker.MatchExtension(".txt"))
+	tr.ExcludeDir(walker.MatchGlobName("node_modules"))
+	sorted := func(seq func(func(walker.Entry) bool)) string {
+		var paths []string
+		for e := range seq {
+			paths = append(paths, e.Path)
+		}
+		slices.Sort(paths)
+		return strings.Join(paths, "; ")
+	}
+	be.Equal(t, sorted(tr.Entries()), sorted(tr.ParallelEntries()))
+	be.Equal(t, "a.txt; dir1/file3.txt; dir2/file5.txt; dir3/sub/file7.txt; file1.txt",
+		sorted(tr.ParallelEntries()))
+	be.NilErr(t, tr.Err())
+
+	tr = walker.New(testFS, ".", walker.OnErrorHalt)
+	tr.SetConcurrency(4)
+	var paths []string
+	for e := range tr.ParallelEntries() {
+		if e.Name() == "dir2" || e.Name() == "dir3" {
+			tr.SkipDir()
+		}
+		paths = append(paths, e.Path)
+	}
+	slices.Sort(paths)
+	be.Equal(t, ". a.txt dir1 dir1/file3.txt dir1/file4.log dir2 dir3 "+
+		"file1.txt file2.log node_modules node_modules/x.txt", strings.Join(paths, " "))
+
+	goroutines := runtime.NumGoroutine()
+	n := 0
+	for range tr.ParallelEntries() {
+		if n++; n == 3 {
+			break
+		}
+	}
+	be.Equal(t, 3, n)
+	be.Equal(t, goroutines, runtime.NumGoroutine())
+}
+
+func TestRanger_ParallelEntries_errors(t *testing.T) {
+	testFS := fstest.MapFS{
+		"a.txt":      &fstest.MapFile{},
+		"bad/b.txt":  &fstest.MapFile{},
+		"good/c.txt": &fstest.MapFile{},
+	}
+	tr := walker.New(badDirFS{testFS}, ".", walker.OnErrorHalt)
+	tr.SetConcurrency(2)
+	for range tr.ParallelEntries() {
+	}
+	be.True(t, errors.Is(tr.Err(), fs.ErrPermission))
+	be.Equal(t, "bad", tr.ErrPath())
+
+	var errs []error
+	tr = walker.New(badDirFS{testFS}, ".", walker.OnErrorCollect(&errs))
+	tr.SetConcurrency(2)
+	var paths []string
+	for e := range tr.ParallelEntries() {
+		paths = append(paths, e.Path)
+	}
+	slices.Sort(paths)
+	be.Equal(t, ". a.txt bad good good/c.txt", strings.Join(paths, " "))
+	be.Equal(t, 1, len(errs))
+}
+
+// slowFS is an fs.FS which waits before reading a directory,
+// like a network filesystem.
+type slowFS struct {
+	fstest.MapFS
+}
+
+func (fsys slowFS) ReadDir(name string) ([]fs.DirEntry, error) {
+	time.Sleep(100 * time.Microsecond)
+	return fsys.MapFS.ReadDir(name)
+}
+
+func BenchmarkRanger_ParallelEntries(b *testing.B) {
+	testFS := fstest.MapFS{}
+	for i := range 50 {
+		for j := range 20 {
+			for k := range 5 {
+				testFS[fmt.Sprintf("dir%d/sub%d/file%d.txt", i, j, k)] = &fstest.MapFile{}
+			}
+		}
+	}
+	for _, fsys := range []struct {
+		name string
+		fs.FS
+	}{
+		{"MapFS", testFS},
+		{"slowFS", slowFS{testFS}},
+	} {
+		for _, n := range []int{1, 4, 16} {
+			b.Run(fmt.Sprintf("%s/concurrency=%d", fsys.name, n), func(b *testing.B) {
+				tr := walker.New(fsys.FS, ".", walker.OnErrorHalt)
+				tr.SetConcurrency(n)
+				for range b.N {
+					for range tr.ParallelEntries() {
+					}
+				}
+			})
+		}
+	}
+}
@@ -0,0 +1,58 @@
+package walker
+
+// EstimateTotal walks the tree once without the file filters
+// and returns the number of entries a walk of the tree visits,
+// along with any error from that walk.
+// Directory filters and options that skip directories are still applied,
+// so directories which would be skipped are not counted,
+// but files are counted whether or not they would be included,
+// which keeps the pass fast even when filters read file contents.
+// Hooks and Trace are not called during the pass.
+// The tree may change before it is walked again,
+// so the count is only an estimate.
+func (tr *Ranger) EstimateTotal() (int, error) {
+	count := tr.Clone()
+	count.includeFiles, count.excludeFiles = nil, nil
+	count.includeErr, count.excludeErr = nil, nil
+	count.budget = 0
+	count.limitPerDir = 0
+	count.traceW = nil
+	count.onEnterDir, count.onLeaveDir, count.onSkipDir = nil, nil, nil
+	count.onProgress = nil
+	n := 0
+	for range count.visit {
+		n++
+	}
+	return n, count.Err()
+}
+
+// ProgressFraction tells the Ranger to call fn
+// as it visits each entry, included or not,
+// with the fraction of the walk completed so far, from 0 to 1.
+// Before walking, the Ranger counts the entries with EstimateTotal.
+// If the tree grows between the count and the walk,
+// the fraction stops at 1 until the walk is done,
+// and if it shrinks, the fraction never reaches 1.
+// On an unchanged tree, the fraction is exactly 1 once every entry has been visited.
+// Pass nil to remove the hook.
+func (tr *Ranger) ProgressFraction(fn func(float64)) {
+	tr.onProgress = fn
+}
+
+// progress wraps yield to report progress to the ProgressFraction hook.
+func (tr *Ranger) progress(yield func(Entry, bool) bool) func(Entry, bool) bool {
+	if tr.onProgress == nil {
+		return yield
+	}
+	total, _ := tr.EstimateTotal()
+	visited := 0
+	return func(e Entry, included bool) bool {
+		visited++
+		fraction := 1.0
+		if visited < total {
+			fraction = float64(visited) / float64(total)
+		}
+		tr.onProgress(fraction)
+		return yield(e, included)
+	}
+}
@@ -0,0 +1,39 @@
+package walker_test
+
+import (
+	"slices"
+	"testing"
+	"testing/fstest"
+
+	"github.com/carlmjohnson/be"
+	"github.com/earthboundkid/walker"
+)
+
+func TestRanger_ProgressFraction(t *testing.T) {
+	testFS := fstest.MapFS{
+		"a.txt":          &fstest.MapFile{},
+		"b.log":          &fstest.MapFile{},
+		"dir1/file3.txt": &fstest.MapFile{},
+		"dir2/file5.txt": &fstest.MapFile{},
+		"skip/file6.txt": &fstest.MapFile{},
+	}
+	tr := walker.New(testFS, ".", walker.OnErrorHalt)
+	tr.Include(walker.MatchExtension(".txt"))
+	tr.ExcludeDir(walker.MatchGlobName("skip"))
+	total, err := tr.EstimateTotal()
+	be.NilErr(t, err)
+	// ., a.txt, b.log, dir1, dir1/file3.txt, dir2, dir2/file5.txt
+	be.Equal(t, 7, total)
+
+	var fractions []float64
+	tr.ProgressFraction(func(f float64) {
+		fractions = append(fractions, f)
+	})
+	paths := slices.Collect(tr.FilePaths())
+	be.NilErr(t, tr.Err())
+	be.Equal(t, 3, len(paths))
+	be.Equal(t, 7, len(fractions))
+	be.True(t, slices.IsSorted(fractions))
+	be.Equal(t, 1.0, fractions[len(fractions)-1])
+	be.True(t, fractions[0] > 0)
+}
@@ -2,87 +2,392 @@
 package walker
 
 import (
+	"fmt"
+	"io"
 	"io/fs"
 	"iter"
+	"os"
+	"path"
 	"path/filepath"
+	"slices"
+	"strings"
+	"time"
 )
 
 // Ranger provides a convenient way to walk through a directory structure.
+// A Ranger may be iterated any number of times, one walk after another,
+// such as to pick up changes to the filesystem;
+// each walk starts afresh with the same configuration,
+// and the walk state reported by Err and Cursor is that of the latest walk.
+// Iterating a Ranger inside a loop over the same Ranger panics;
+// see Clone.
 type Ranger struct {
 	fsys                       fs.FS
+	readDir                    func(string) ([]fs.DirEntry, error)
 	root                       string
 	isWalking                  bool
 	skipDir                    bool
 	lastErr                    error
+	errEntry                   Entry
 	includeFiles, excludeFiles FilterFunc
 	includeDirs, excludeDirs   FilterFunc
+	includeErr, excludeErr     ErrFilterFunc
 	erp                        ErrorPolicy
+	traceW                     io.Writer
+	resumeAfter, lastYielded   string
+	sampleDirs, dirsDescended  int
+	sortModTime, sortDesc      bool
+	budget, spent              int64
+	budgetInclusive            bool
+	absPaths                   bool
+	absRoot                    string
+	now                        func() time.Time
+	dedup, caseFold            bool
+	seenPaths                  map[string]bool
+	noFollowDirs               bool
+	onEnterDir, onLeaveDir     func(Entry)
+	onSkipDir                  func(Entry, string)
+	limitPerDir                int
+	perDir                     map[string]int
+	requireRoot                bool
+	passFilterErrs             bool
+	passWalkErrs               bool
+	stableOrder                bool
+	sortSiblings               func(a, b Entry) int
+	renderPath                 func(Entry) string
+	decodeText                 bool
+	contentLimit               int64
+	openSem                    chan struct{}
+	maxVisits, visits          int
+	omitDirs                   bool
+	coalesce                   bool
+	errCauses                  []error
+	rangeFrom, rangeTo         []string
+	yieldErr                   error
+	onProgress                 func(float64)
+	followLinks                bool
+	linkParents                []string
+	concurrency                int
+	breadthFirst               bool
 }
 
-// includeAll is a default FilterFunc that includes all files and directories.
-func includeAll(Entry) bool { return true }
-
-// excludeNone is a default FilterFunc that includes all files and directories.
-func excludeNone(Entry) bool { return false }
-
 // New creates a new *Ranger with the given root directory.
 // Pass a nil fsys to use filepath.WalkFunc and walk the OS filesystem instead of an fs.FS.
 // The default Ranger includes all files and directories.
 // There is no default ErrorPolicy.
+//
+// When walking the OS filesystem, root is cleaned with filepath.Clean,
+// so a root such as "foo/../bar" walks "bar" and yields paths beginning with "bar".
+// An fs.FS root must be a valid path according to fs.ValidPath,
+// or the walk reports an error wrapping fs.ErrInvalid.
 func New(fsys fs.FS, root string, erp ErrorPolicy) Ranger {
+	if fsys == nil {
+		root = filepath.Clean(root)
+	}
 	return Ranger{
-		fsys:         fsys,
-		root:         root,
-		includeFiles: includeAll,
-		excludeFiles: excludeNone,
-		includeDirs:  includeAll,
-		excludeDirs:  excludeNone,
-		erp:          erp,
+		fsys: fsys,
+		root: root,
+		erp:  erp,
+	}
+}
+
+// List walks the OS filesystem from root
+// and returns the paths of the files matching filter,
+// ignoring directories.
+// A nil filter matches every file.
+// It stops at the first error and returns it.
+func List(root string, filter FilterFunc) ([]string, error) {
+	tr := New(nil, root, OnErrorHalt)
+	tr.Include(filter)
+	paths := slices.Collect(tr.FilePaths())
+	return paths, tr.Err()
+}
+
+// Find walks the OS filesystem from root
+// and returns the paths of the files whose name is exactly name,
+// ignoring directories.
+// Errors reading the tree, such as unreadable subdirectories, are ignored,
+// but it returns an error if root cannot be walked at all.
+func Find(root, name string) ([]string, error) {
+	tr := New(nil, root, OnErrorIgnore)
+	if err := tr.Validate(); err != nil {
+		return nil, err
 	}
+	tr.Include(func(e Entry) bool {
+		return e.Name() == name
+	})
+	return slices.Collect(tr.FilePaths()), nil
+}
+
+// Clone returns a copy of the Ranger with the same configuration
+// but without any walk state, such as an in-progress walk or a previous error.
+// A single Ranger cannot be iterated concurrently,
+// but each clone can be iterated independently,
+// so a configured Ranger can serve as a factory for concurrent walks.
+// Clones share their filters and ErrorPolicy,
+// which must be safe for concurrent use if the clones are used concurrently.
+func (tr *Ranger) Clone() Ranger {
+	clone := *tr
+	clone.isWalking = false
+	clone.skipDir = false
+	clone.lastErr = nil
+	clone.errEntry = Entry{}
+	clone.lastYielded = ""
+	clone.passFilterErrs = false
+	clone.passWalkErrs = false
+	clone.yieldErr = nil
+	return clone
 }
 
 // Entries returns a sequence of Entries for matching files and directories.
 func (tr *Ranger) Entries() iter.Seq[Entry] {
 	return func(yield func(Entry) bool) {
-		for e := range tr.walk {
-			if tr.HasError() {
-				if !tr.erp(tr.Err(), e) {
-					return
+		if tr.omitDirs {
+			yieldFile := yield
+			yield = func(e Entry) bool {
+				return e.IsDir() || yieldFile(e)
+			}
+		}
+		if tr.sortModTime {
+			tr.sortedByModTime(yield)
+			return
+		}
+		for e, included := range tr.visit {
+			if included && !yield(e) {
+				return
+			}
+		}
+	}
+}
+
+// visit applies the error policy and directory filters to the walk.
+// It yields each entry that was not skipped
+// along with whether it passed the file filters.
+func (tr *Ranger) visit(yield func(Entry, bool) bool) {
+	yield = tr.progress(yield)
+	var openDirs []Entry
+	defer func() {
+		tr.leaveDirs(&openDirs, nil)
+	}()
+	for e := range tr.walk {
+		if !tr.breadthFirst {
+			tr.leaveDirs(&openDirs, &e)
+		}
+		if tr.HasError() && tr.passWalkErrs {
+			err := tr.Err()
+			tr.lastErr = nil
+			tr.trace("include", e, err.Error())
+			tr.yieldErr = err
+			ok := yield(tr.absolute(e), true)
+			tr.yieldErr = nil
+			if !ok {
+				return
+			}
+			continue
+		}
+		if tr.HasError() {
+			if !tr.handleError(tr.Err(), e) {
+				tr.trace("halt", e, tr.Err().Error())
+				return
+			}
+			tr.trace("ignore", e, tr.Err().Error())
+			continue
+		}
+
+		if tr.skipResumed(e) || tr.beforeRange(e) {
+			continue
+		}
+		if tr.pastRange(e) {
+			tr.trace("halt", e, "range")
+			return
+		}
+
+		if reason := tr.rejectDup(e); reason != "" {
+			if e.IsDir() {
+				tr.skipDirFor(e, reason)
+			} else {
+				tr.trace("exclude", e, reason)
+			}
+			continue
+		}
+
+		if e.Dir() == tr.root || e.IsDir() {
+			if reason := tr.rejectDir(e); reason != "" {
+				if e.Dir() == tr.root {
+					tr.trace("exclude", e, reason)
+				} else {
+					tr.skipDirFor(e, reason)
 				}
 				continue
 			}
+		}
 
-			switch {
-			case e.Dir() == tr.root && (tr.excludeDirs(e) || !tr.includeDirs(e)):
-				continue
-			case e.IsDir() && (tr.excludeDirs(e) || !tr.includeDirs(e)):
-				tr.SkipDir()
+		if tr.noFollowDirs && e.IsDir() && e.IsSymlink() && e.Path != tr.root {
+			tr.SkipDir()
+			if tr.onSkipDir != nil {
+				tr.onSkipDir(tr.absolute(e), "no-follow-dirs")
+			}
+		}
+
+		if e.IsDir() && e.Path != tr.root && tr.sampleDirs > 0 {
+			if tr.dirsDescended >= tr.sampleDirs {
+				tr.skipDirFor(e, "sample-dirs")
 				continue
 			}
+			tr.dirsDescended++
+		}
+
+		if e.IsDir() && !tr.skipDir && (tr.onEnterDir != nil || tr.onLeaveDir != nil) {
+			openDirs = append(openDirs, e)
+			if tr.onEnterDir != nil {
+				tr.onEnterDir(tr.absolute(e))
+			}
+		}
 
-			if tr.excludeFiles(e) || !tr.includeFiles(e) {
+		reason := tr.rejectFile(e)
+		if reason == "" {
+			var err error
+			reason, err = tr.rejectFileErr(e)
+			if err != nil && tr.passFilterErrs {
+				tr.trace("include", e, err.Error())
+				tr.yieldErr = err
+				ok := yield(tr.absolute(e), true)
+				tr.yieldErr = nil
+				if !ok {
+					return
+				}
 				continue
 			}
-			if !yield(e) {
+			if err != nil {
+				tr.lastErr, tr.errEntry = err, e
+				if !tr.handleError(err, e) {
+					tr.trace("halt", e, err.Error())
+					return
+				}
+				tr.trace("ignore", e, err.Error())
+			}
+		}
+		if reason == "" && tr.overDirLimit(e) {
+			reason = "limit-per-dir"
+		}
+		if reason != "" {
+			tr.trace("exclude", e, reason)
+			if !yield(tr.absolute(e), false) {
 				return
 			}
+			continue
+		}
+		last := tr.exceedsBudget(e)
+		if last && !tr.budgetInclusive {
+			tr.trace("halt", e, "size-budget")
+			return
 		}
+		tr.trace("include", e, "")
+		tr.lastYielded = e.Path
+		if !yield(tr.absolute(e), true) || last {
+			return
+		}
+	}
+}
+
+// skipDirFor skips the directory e because of reason,
+// tracing it and calling the OnSkipDir hook.
+func (tr *Ranger) skipDirFor(e Entry, reason string) {
+	tr.SkipDir()
+	tr.trace("skip", e, reason)
+	if tr.onSkipDir != nil {
+		tr.onSkipDir(tr.absolute(e), reason)
+	}
+}
+
+// leaveDirs pops the directories which do not contain e off of openDirs,
+// calling the OnLeaveDir hook for each.
+// Pass a nil e to leave every directory.
+func (tr *Ranger) leaveDirs(openDirs *[]Entry, e *Entry) {
+	for len(*openDirs) > 0 {
+		top := (*openDirs)[len(*openDirs)-1]
+		if e != nil && e.isWithin(top) {
+			return
+		}
+		*openDirs = (*openDirs)[:len(*openDirs)-1]
+		if tr.onLeaveDir != nil {
+			tr.onLeaveDir(tr.absolute(top))
+		}
+	}
+}
+
+// rejectDir returns the name of the directory filter that rejects e, if any.
+// Nil filters include everything and exclude nothing.
+func (tr *Ranger) rejectDir(e Entry) string {
+	switch {
+	case tr.excludeDirs != nil && tr.excludeDirs(e):
+		return "exclude-dirs"
+	case tr.includeDirs != nil && !tr.includeDirs(e):
+		return "include-dirs"
+	}
+	return ""
+}
+
+// rejectFile returns the name of the file filter that rejects e, if any.
+// Nil filters include everything and exclude nothing.
+func (tr *Ranger) rejectFile(e Entry) string {
+	switch {
+	case tr.excludeFiles != nil && tr.excludeFiles(e):
+		return "exclude-files"
+	case tr.includeFiles != nil && !tr.includeFiles(e):
+		return "include-files"
+	}
+	return ""
+}
+
+// rejectFileErr returns the name of the ErrFilterFunc that rejects e, if any,
+// and any error it returned.
+func (tr *Ranger) rejectFileErr(e Entry) (string, error) {
+	if tr.excludeErr != nil {
+		if matched, err := tr.excludeErr(e); err != nil || matched {
+			return "exclude-files", err
+		}
+	}
+	if tr.includeErr != nil {
+		if matched, err := tr.includeErr(e); err != nil || !matched {
+			return "include-files", err
+		}
+	}
+	return "", nil
+}
+
+// trace logs a filtering decision if tracing is enabled.
+func (tr *Ranger) trace(verdict string, e Entry, reason string) {
+	if tr.traceW == nil {
+		return
+	}
+	if reason == "" {
+		fmt.Fprintf(tr.traceW, "%s %s\n", verdict, e.Path)
+		return
 	}
+	fmt.Fprintf(tr.traceW, "%s %s (%s)\n", verdict, e.Path, reason)
 }
 
 // walk is lower level and doesn't know about the error policy or filters
 func (tr *Ranger) walk(yield func(Entry) bool) {
-	if tr.isWalking {
-		panic("already walking")
-	}
-	if tr.erp == nil {
-		panic("no error policy set")
+	e, ok := tr.startWalk()
+	if !ok {
+		return
 	}
-	var e Entry
-	e.useFilepath = tr.fsys == nil
-	tr.isWalking = true
+	defer func() { tr.isWalking = false }()
 	walkDir := func(path string, d fs.DirEntry, err error) error {
-		e.Path, e.DirEntry, tr.lastErr = path, d, err
+		e.Path, e.DirEntry, tr.lastErr = path, cacheInfo(d), err
+		if err != nil {
+			tr.errEntry = e
+		}
+		if tr.maxVisits > 0 {
+			if tr.visits >= tr.maxVisits {
+				tr.lastErr, tr.errEntry = ErrMaxVisitsExceeded, e
+				tr.trace("halt", e, "max-visits")
+				return fs.SkipAll
+			}
+			tr.visits++
+		}
 		if !yield(e) {
 			return fs.SkipAll
 		}
@@ -92,12 +397,86 @@ func (tr *Ranger) walk(yield func(Entry) bool) {
 		}
 		return nil
 	}
-	if tr.fsys != nil {
-		_ = fs.WalkDir(tr.fsys, tr.root, walkDir)
-	} else {
-		_ = filepath.WalkDir(tr.root, walkDir)
+	tr.walkRoot(walkDir)
+}
+
+// startWalk resets the walk state and marks the Ranger as walking.
+// It returns a template Entry for the walk,
+// or false if RequireRoot is set and the root is invalid.
+// The caller must clear isWalking when the walk is done.
+func (tr *Ranger) startWalk() (Entry, bool) {
+	if tr.isWalking {
+		panic("walker: Ranger is already walking; " +
+			"iterating a Ranger inside a loop over the same Ranger is not allowed, " +
+			"so use Clone to make an independent Ranger for the inner loop")
+	}
+	if tr.erp == nil {
+		panic("no error policy set")
+	}
+	tr.skipDir = false
+	tr.lastErr = nil
+	tr.errEntry = Entry{}
+	tr.lastYielded = ""
+	if tr.requireRoot {
+		if err := tr.Validate(); err != nil {
+			tr.lastErr = err
+			tr.errEntry = Entry{Path: tr.root, root: tr.root, useFilepath: tr.useFilepath()}
+			return Entry{}, false
+		}
+	}
+	var e Entry
+	e.root = tr.root
+	e.fsys = tr.fsys
+	e.openSem = tr.openSem
+	e.useFilepath = tr.useFilepath()
+	tr.isWalking = true
+	tr.seenPaths = nil
+	tr.perDir = nil
+	tr.absRoot = ""
+	if tr.absPaths && e.useFilepath {
+		if abs, err := filepath.Abs(tr.root); err == nil {
+			tr.absRoot = abs
+		}
 	}
-	tr.isWalking = false
+	tr.dirsDescended = 0
+	tr.spent = 0
+	tr.visits = 0
+	tr.errCauses = nil
+	return e, true
+}
+
+// Validate checks that the Ranger's root exists
+// and returns a descriptive error if it does not.
+// Walking a missing root reports the error through the ErrorPolicy and Err(),
+// but Validate allows checking up front.
+func (tr *Ranger) Validate() error {
+	var err error
+	switch {
+	case tr.readDir != nil:
+		_, err = tr.readDir(tr.root)
+	case tr.fsys != nil && !fs.ValidPath(tr.root):
+		err = fs.ErrInvalid
+	case tr.fsys != nil:
+		_, err = fs.Stat(tr.fsys, tr.root)
+	default:
+		_, err = os.Stat(tr.root)
+	}
+	if err != nil {
+		return fmt.Errorf("walker: invalid root %q: %w", tr.root, err)
+	}
+	return nil
+}
+
+// RequireRoot tells the Ranger whether to check the root with Validate
+// before each walk.
+// If the check fails, nothing is yielded
+// and Err returns the error from Validate
+// without passing it to the ErrorPolicy,
+// so a missing root is reported even by a policy such as OnErrorIgnore
+// that would otherwise make it look like an empty directory.
+// The default is false.
+func (tr *Ranger) RequireRoot(require bool) {
+	tr.requireRoot = require
 }
 
 // Err returns the last error encountered during walking, if any.
@@ -105,6 +484,18 @@ func (tr *Ranger) Err() error {
 	return tr.lastErr
 }
 
+// ErrPath returns the path of the entry that caused the error reported by Err(),
+// as it was or would have been yielded,
+// or "" if there is no error.
+// It remains available after the walk is done,
+// so a walk halted by an error can report where it stopped.
+func (tr *Ranger) ErrPath() string {
+	if tr.lastErr == nil {
+		return ""
+	}
+	return tr.absolute(tr.errEntry).Path
+}
+
 // HasError returns true if an error has been encountered during the last walk.
 func (tr *Ranger) HasError() bool {
 	return tr.Err() != nil
@@ -119,30 +510,536 @@ func (tr *Ranger) SkipDir() {
 	tr.skipDir = true
 }
 
+// TrySkipDir is like SkipDir,
+// but instead of panicking when the Ranger is not iterating,
+// it does nothing and returns false.
+// It returns true if the current directory will be skipped.
+func (tr *Ranger) TrySkipDir() bool {
+	if !tr.isWalking {
+		return false
+	}
+	tr.skipDir = true
+	return true
+}
+
 // Include tells the Ranger to include matching files when iterating.
 // The default is to include all files.
+// Include replaces any previously set include filter.
+// It may be called while iterating, from the iterating goroutine,
+// to change the filter for the entries that follow.
 func (tr *Ranger) Include(f FilterFunc) {
 	tr.includeFiles = f
 }
 
 // Exclude tells the Ranger to exclude matching files when iterating.
 // Files matched by Exclude take precedence over files matched by Include.
+// Exclude replaces any previously set exclude filter.
+// Like Include, it may be called while iterating.
 func (tr *Ranger) Exclude(f FilterFunc) {
 	tr.excludeFiles = f
 }
 
 // IncludeDir tells the Ranger to recursing into matching directories.
 // The default is to include all directories.
+// IncludeDir replaces any previously set directory include filter.
+// Like Include, it may be called while iterating.
 func (tr *Ranger) IncludeDir(f FilterFunc) {
 	tr.includeDirs = f
 }
 
 // ExcludeDir tells the Ranger not to recursing into matching directories.
 // Directories matched by ExcludeDir take precedence over directories matched by IncludeDir.
+// ExcludeDir replaces any previously set directory exclude filter.
+// Like Include, it may be called while iterating.
 func (tr *Ranger) ExcludeDir(f FilterFunc) {
 	tr.excludeDirs = f
 }
 
+// AddInclude is like Include,
+// but files matching f are included in addition to
+// files matched by any previously set include filter.
+func (tr *Ranger) AddInclude(f FilterFunc) {
+	tr.includeFiles = orFilter(tr.includeFiles, f)
+}
+
+// AddExclude is like Exclude,
+// but files matching f are excluded in addition to
+// files matched by any previously set exclude filter.
+func (tr *Ranger) AddExclude(f FilterFunc) {
+	tr.excludeFiles = orFilter(tr.excludeFiles, f)
+}
+
+// AddIncludeDir is like IncludeDir,
+// but directories matching f are included in addition to
+// directories matched by any previously set directory include filter.
+func (tr *Ranger) AddIncludeDir(f FilterFunc) {
+	tr.includeDirs = orFilter(tr.includeDirs, f)
+}
+
+// AddExcludeDir is like ExcludeDir,
+// but directories matching f are excluded in addition to
+// directories matched by any previously set directory exclude filter.
+func (tr *Ranger) AddExcludeDir(f FilterFunc) {
+	tr.excludeDirs = orFilter(tr.excludeDirs, f)
+}
+
+// IncludeErr is like Include, but for a filter that can fail.
+// Errors returned by f are passed to the ErrorPolicy,
+// and entries that cause an error are not included.
+// Files must match both the include filter and IncludeErr filter to be included.
+func (tr *Ranger) IncludeErr(f ErrFilterFunc) {
+	tr.includeErr = f
+}
+
+// ExcludeErr is like Exclude, but for a filter that can fail.
+// Errors returned by f are passed to the ErrorPolicy,
+// and entries that cause an error are not included.
+// Files matched by either the exclude filter or ExcludeErr filter are excluded.
+func (tr *Ranger) ExcludeErr(f ErrFilterFunc) {
+	tr.excludeErr = f
+}
+
+// ExcludeHidden tells the Ranger to exclude dot files
+// and not to recurse into dot directories.
+// It is shorthand for calling AddExclude and AddExcludeDir with MatchDotFile.
+func (tr *Ranger) ExcludeHidden() {
+	tr.AddExclude(MatchDotFile)
+	tr.AddExcludeDir(MatchDotFile)
+}
+
+// ExcludePaths tells the Ranger to exclude the files at exactly the given paths
+// and not to recurse into directories at exactly those paths.
+// Each path may be given either as a full path, as Entry.Path would report it,
+// or relative to the root of the walk.
+// Paths are compared after cleaning and converting to forward slashes,
+// using a set, so long lists of paths remain fast.
+// It calls AddExclude and AddExcludeDir.
+func (tr *Ranger) ExcludePaths(paths ...string) {
+	set := make(map[string]struct{}, len(paths))
+	for _, p := range paths {
+		set[filepath.ToSlash(filepath.Clean(p))] = struct{}{}
+	}
+	match := func(e Entry) bool {
+		if _, ok := set[e.slashPath()]; ok {
+			return true
+		}
+		_, ok := set[filepath.ToSlash(e.RelRoot())]
+		return ok
+	}
+	tr.AddExclude(match)
+	tr.AddExcludeDir(match)
+}
+
+// OnlyUnder tells the Ranger to walk only the given directories,
+// whose paths are slash separated and relative to the root,
+// including only the files within them
+// and descending only into them, their subdirectories, and their ancestors.
+// Other directories are skipped as soon as they are reached,
+// so it is much faster than an include filter
+// when only a few directories of a large tree are of interest.
+// Like IncludeAll, it narrows any file and directory include filters
+// set earlier rather than replacing or widening them,
+// so files and directories must also match those filters.
+func (tr *Ranger) OnlyUnder(dirs ...string) {
+	split := make([][]string, len(dirs))
+	for i, dir := range dirs {
+		split[i] = splitRange(dir)
+		if split[i] == nil {
+			split[i] = []string{}
+		}
+	}
+	under := func(e Entry) bool {
+		segs := e.relSegments()
+		return slices.ContainsFunc(split, func(dir []string) bool {
+			return isPrefix(dir, segs)
+		})
+	}
+	tr.IncludeAll(under)
+	var underOrAbove FilterFunc = func(e Entry) bool {
+		if under(e) {
+			return true
+		}
+		segs := e.relSegments()
+		return e.IsDir() && slices.ContainsFunc(split, func(dir []string) bool {
+			return isPrefix(segs, dir)
+		})
+	}
+	if tr.includeDirs != nil {
+		underOrAbove = And(tr.includeDirs, underOrAbove)
+	}
+	tr.includeDirs = underOrAbove
+}
+
+// IncludePath tells the Ranger to include files
+// whose path relative to the root matches any of the glob patterns,
+// as with MatchGlobRel,
+// and not to recurse into directories which cannot contain a match.
+// A directory is descended into only if each of its path elements
+// matches the corresponding element of some pattern
+// and that pattern has more elements than the directory's path,
+// so "dir1/*.txt" descends into dir1 but not dir2 or dir1/sub.
+// Because patterns are matched element by element,
+// they cannot match across directories.
+// It calls AddInclude and AddIncludeDir.
+func (tr *Ranger) IncludePath(patterns ...string) {
+	split := make([][]string, len(patterns))
+	for i, pattern := range patterns {
+		split[i] = strings.Split(filepath.ToSlash(pattern), "/")
+	}
+	tr.AddInclude(MatchGlobRel(patterns...))
+	tr.AddIncludeDir(func(e Entry) bool {
+		// Files directly in the root are also checked by directory filters,
+		// but they are left to the include filter.
+		if !e.IsDir() {
+			return true
+		}
+		segs := e.relSegments()
+		for _, pattern := range split {
+			if len(segs) < len(pattern) && matchSegments(pattern, segs) {
+				return true
+			}
+		}
+		return false
+	})
+}
+
+// matchSegments reports whether each of segs matches
+// the corresponding element of pattern.
+func matchSegments(pattern, segs []string) bool {
+	for i, seg := range segs {
+		if matched, err := path.Match(pattern[i], seg); err != nil || !matched {
+			return false
+		}
+	}
+	return true
+}
+
+// IncludeAll is like Include,
+// but files must match f as well as
+// any previously set include filter to be included.
+func (tr *Ranger) IncludeAll(f FilterFunc) {
+	if tr.includeFiles == nil {
+		tr.includeFiles = f
+		return
+	}
+	tr.includeFiles = And(tr.includeFiles, f)
+}
+
+// IncludeDirsInOutput tells the Ranger whether Entries
+// and the iterators built on it, such as Paths, yield directories.
+// Directory filters such as IncludeDir and ExcludeDir
+// still control which directories are descended into.
+// The default is true.
+func (tr *Ranger) IncludeDirsInOutput(include bool) {
+	tr.omitDirs = !include
+}
+
+// SampleDirs tells the Ranger to descend into at most n directories
+// below the root, skipping any further directories.
+// Because directories are walked in lexical order,
+// the sample is the same on every walk of an unchanged tree.
+// Pass 0 to descend into all directories, which is the default.
+func (tr *Ranger) SampleDirs(n int) {
+	tr.sampleDirs = n
+}
+
+// SortedByModTime tells the Ranger to yield entries
+// in order of modification time, oldest first,
+// or newest first if desc is true.
+// Entries with equal modification times keep their lexical order.
+// Sorting requires walking the whole tree and holding every matching entry in memory
+// before the first entry is yielded, so results cannot be streamed
+// and SkipDir cannot be called during iteration.
+func (tr *Ranger) SortedByModTime(desc bool) {
+	tr.sortModTime = true
+	tr.sortDesc = desc
+}
+
+func (tr *Ranger) sortedByModTime(yield func(Entry) bool) {
+	type timedEntry struct {
+		Entry
+		modTime time.Time
+	}
+	var entries []timedEntry
+	for e, included := range tr.visit {
+		if included {
+			t, _ := modTime(e)
+			entries = append(entries, timedEntry{e, t})
+		}
+	}
+	slices.SortStableFunc(entries, func(a, b timedEntry) int {
+		if tr.sortDesc {
+			return b.modTime.Compare(a.modTime)
+		}
+		return a.modTime.Compare(b.modTime)
+	})
+	for _, e := range entries {
+		if !yield(e.Entry) {
+			return
+		}
+	}
+}
+
+// SizeBudget tells the Ranger to stop walking once
+// the total size of the matching files it has yielded would exceed bytes.
+// If inclusive is true, the file which crosses the budget is yielded before stopping;
+// otherwise it is not.
+// Directories do not count toward the budget.
+// Pass 0 for no budget, which is the default.
+func (tr *Ranger) SizeBudget(bytes int64, inclusive bool) {
+	tr.budget = bytes
+	tr.budgetInclusive = inclusive
+}
+
+// exceedsBudget adds the size of e to the running total
+// and reports whether the total is now over budget.
+func (tr *Ranger) exceedsBudget(e Entry) bool {
+	if tr.budget <= 0 || e.IsDir() {
+		return false
+	}
+	n, _ := size(e)
+	tr.spent += n
+	return tr.spent > tr.budget
+}
+
+// AbsolutePaths tells the Ranger whether to yield absolute paths
+// when walking the OS filesystem with a relative root.
+// The root is resolved with filepath.Abs once at the start of each walk,
+// and filters still see the paths relative to the root as given.
+// If the working directory cannot be determined, paths are left unchanged.
+// AbsolutePaths has no effect when walking an fs.FS,
+// whose paths are always relative.
+func (tr *Ranger) AbsolutePaths(abs bool) {
+	tr.absPaths = abs
+}
+
+// absolute rewrites e to be under absRoot, if it is set.
+func (tr *Ranger) absolute(e Entry) Entry {
+	if tr.absRoot == "" {
+		return e
+	}
+	e.Path = filepath.Join(tr.absRoot, e.RelRoot())
+	e.root = tr.absRoot
+	return e
+}
+
+// NoFollowDirs tells the Ranger whether to refuse to descend into
+// entries which are reported as both a directory and a symbolic link.
+// Some fs.FS implementations transparently follow symbolic links to directories,
+// which can cause unexpected descent into other parts of a filesystem or cycles.
+// The links themselves are still yielded if they match the filters.
+// The OS filesystem and os.DirFS never descend into symbolic links,
+// so NoFollowDirs has no effect on them.
+func (tr *Ranger) NoFollowDirs(noFollow bool) {
+	tr.noFollowDirs = noFollow
+}
+
+// StableOrder tells the Ranger whether to sort the children of each directory
+// lexically by name before descending,
+// regardless of the order in which the backend lists them.
+// Sorting buffers each directory listing in full before walking it,
+// which costs a little memory for very large directories.
+// The OS filesystem and most fs.FS implementations already list in lexical order,
+// but a NewFunc readDir or an fs.ReadDirFS may not.
+func (tr *Ranger) StableOrder(stable bool) {
+	tr.stableOrder = stable
+}
+
+// SortBy tells the Ranger to walk the children of each directory
+// in the order given by cmp, as with slices.SortStableFunc,
+// such as CompareBySize to walk smaller files first
+// or a function reversing CompareByModTime to walk newer files first.
+// Children which compare equal are walked in lexical order.
+// The walk is still depth first,
+// so each directory's contents are walked as soon as the directory is reached,
+// and SkipDir works as usual.
+// Sorting buffers each directory listing in full before walking it,
+// so the memory used grows with the size of the largest directory,
+// and comparing by size or modification time reads the info of every child.
+// Options that rely on lexical order, such as Range and NewResume,
+// should not be combined with SortBy.
+// Pass nil to walk in the order the backend lists entries, which is the default.
+func (tr *Ranger) SortBy(cmp func(a, b Entry) int) {
+	tr.sortSiblings = cmp
+}
+
+// BreadthFirst tells the Ranger whether to walk the tree in level order,
+// yielding every entry at one depth before any entry at the next,
+// so the contents of a directory are walked after all of its siblings
+// rather than as soon as the directory is reached.
+// Directories are queued as they are yielded, unless SkipDir is called,
+// so the memory used grows with the number of directories at the widest level.
+// The children of each directory are still listed in backend order,
+// or sorted by StableOrder or SortBy.
+// Options that rely on lexical order, such as Range and NewResume,
+// should not be combined with BreadthFirst,
+// and because a directory's contents are not walked together,
+// the OnLeaveDir hook is called for every directory entered
+// only once the walk is done.
+// ForEachDir, EachPostOrder, and DirStats need a directory's contents
+// to follow it, so they ignore BreadthFirst and always walk depth first.
+// The default is false, which walks depth first.
+func (tr *Ranger) BreadthFirst(breadthFirst bool) {
+	tr.breadthFirst = breadthFirst
+}
+
+// MaxVisits tells the Ranger to visit at most n entries,
+// counting every entry the walk reaches,
+// including those excluded by filters and those reported with errors.
+// Reaching another entry after n halts the walk
+// and sets Err() to ErrMaxVisitsExceeded without consulting the ErrorPolicy.
+// Pass n less than 1 for no limit, which is the default.
+func (tr *Ranger) MaxVisits(n int) {
+	tr.maxVisits = n
+}
+
+// OpenFileLimit tells the Ranger to have at most n files open at once
+// for content filters such as MatchContentContains and MatchReadable
+// and for Entry.Open and Entry.WriteTo.
+// Opening a file beyond the limit waits until another is closed.
+// Clones share the limit, so it also applies across concurrent walks.
+// Files opened with Entry.OpenChild are not counted.
+// Pass n less than 1 for no limit, which is the default.
+func (tr *Ranger) OpenFileLimit(n int) {
+	tr.openSem = nil
+	if n > 0 {
+		tr.openSem = make(chan struct{}, n)
+	}
+}
+
+// LimitPerDir tells the Ranger to include at most n matching files
+// directly inside each directory, excluding any further files.
+// Files are counted in lexical order,
+// so the same files are included on every walk of an unchanged tree.
+// The count for each directory is held in memory until the next walk.
+// Pass 0 for no limit, which is the default.
+func (tr *Ranger) LimitPerDir(n int) {
+	tr.limitPerDir = n
+}
+
+// overDirLimit counts e toward the limit for its directory
+// and reports whether the limit was already reached.
+func (tr *Ranger) overDirLimit(e Entry) bool {
+	if tr.limitPerDir <= 0 || e.IsDir() {
+		return false
+	}
+	if tr.perDir == nil {
+		tr.perDir = make(map[string]int)
+	}
+	dir := e.Dir()
+	if tr.perDir[dir] >= tr.limitPerDir {
+		return true
+	}
+	tr.perDir[dir]++
+	return false
+}
+
+// OnEnterDir sets a hook which is called
+// with each directory the Ranger is about to descend into,
+// before any of its contents are visited.
+// Pass nil to remove the hook.
+func (tr *Ranger) OnEnterDir(fn func(Entry)) {
+	tr.onEnterDir = fn
+}
+
+// OnLeaveDir sets a hook which is called
+// with each directory passed to the OnEnterDir hook
+// once all of its contents have been visited,
+// so subdirectories are left before their parents.
+// Every directory entered is left, even if the walk stops early.
+// Pass nil to remove the hook.
+func (tr *Ranger) OnLeaveDir(fn func(Entry)) {
+	tr.onLeaveDir = fn
+}
+
+// OnSkipDir sets a hook which is called
+// with each directory the Ranger declines to descend into
+// and the reason, which names the cause as in Trace output:
+// "exclude-dirs", "include-dirs", "sample-dirs", "dedup", "case-fold-dedup",
+// or "no-follow-dirs".
+// Directories skipped by calling SkipDir directly are not reported.
+// Pass nil to remove the hook.
+func (tr *Ranger) OnSkipDir(fn func(e Entry, reason string)) {
+	tr.onSkipDir = fn
+}
+
+// DedupPaths tells the Ranger to skip any entry
+// whose cleaned path, as returned by Entry.Key,
+// was already visited during the walk.
+// Directories which are skipped this way are not descended into.
+// This is useful with a NewFunc Ranger whose readDir
+// may list the same entry more than once,
+// such as one which merges overlapping directory trees.
+// The paths are held in memory until the next walk.
+func (tr *Ranger) DedupPaths() {
+	tr.dedup = true
+}
+
+// CaseFoldDedup tells the Ranger to skip any entry
+// whose path differs only in case from an entry already visited during the walk.
+// Directories which are skipped this way are not descended into.
+// This avoids visiting the same file twice on case-insensitive filesystems,
+// such as the defaults on macOS and Windows,
+// when paths reach it under different casings.
+// The first casing visited is the one yielded.
+// The lowercased paths are held in memory until the next walk.
+func (tr *Ranger) CaseFoldDedup() {
+	tr.caseFold = true
+}
+
+// rejectDup records the path of e and returns the name of the option
+// that rejects it as a duplicate of a path already recorded, if any.
+func (tr *Ranger) rejectDup(e Entry) string {
+	var key, reason string
+	switch {
+	case tr.caseFold:
+		key, reason = strings.ToLower(e.Key()), "case-fold-dedup"
+	case tr.dedup:
+		key, reason = e.Key(), "dedup"
+	default:
+		return ""
+	}
+	if tr.seenPaths == nil {
+		tr.seenPaths = make(map[string]bool)
+	}
+	if tr.seenPaths[key] {
+		return reason
+	}
+	tr.seenPaths[key] = true
+	return ""
+}
+
+// Clock sets the function the Ranger uses to get the current time
+// for methods that depend on it, such as AgeBuckets.
+// Pass nil to use time.Now, which is the default.
+func (tr *Ranger) Clock(now func() time.Time) {
+	tr.now = now
+}
+
+// currentTime returns the current time according to the Ranger's Clock.
+func (tr *Ranger) currentTime() time.Time {
+	if tr.now == nil {
+		return time.Now()
+	}
+	return tr.now()
+}
+
+// orFilter combines two FilterFuncs with Or, treating a nil FilterFunc as unset.
+func orFilter(a, b FilterFunc) FilterFunc {
+	if a == nil {
+		return b
+	}
+	return Or(a, b)
+}
+
+// Trace tells the Ranger to write a line to w for each entry it visits,
+// recording whether the entry was included, excluded, or skipped
+// and which filter made the decision.
+// Pass nil to turn tracing off, which is the default.
+func (tr *Ranger) Trace(w io.Writer) {
+	tr.traceW = w
+}
+
 // FileEntries returns a sequence of Entries for matching files, ignoring directories.
 func (tr *Ranger) FileEntries() iter.Seq[Entry] {
 	return func(yield func(Entry) bool) {
@@ -159,9 +1056,229 @@ func (tr *Ranger) FileEntries() iter.Seq[Entry] {
 func (tr *Ranger) FilePaths() iter.Seq[string] {
 	return func(yield func(string) bool) {
 		for e := range tr.FileEntries() {
-			if !yield(e.Path) {
+			if !yield(tr.outputPath(e)) {
+				return
+			}
+		}
+	}
+}
+
+// Paths returns a sequence of paths for matching files and directories.
+func (tr *Ranger) Paths() iter.Seq[string] {
+	return func(yield func(string) bool) {
+		for e := range tr.Entries() {
+			if !yield(tr.outputPath(e)) {
+				return
+			}
+		}
+	}
+}
+
+// DirEntries returns a sequence of Entries for directories, ignoring files.
+// Directories are yielded if they pass the directory filters,
+// such as IncludeDir and ExcludeDir, so the root is yielded first
+// unless a directory filter excludes it.
+// Unlike Entries, DirEntries ignores the file filters, such as Include,
+// and IncludeDirsInOutput.
+func (tr *Ranger) DirEntries() iter.Seq[Entry] {
+	return func(yield func(Entry) bool) {
+		for e := range tr.visit {
+			if e.IsDir() && !yield(e) {
+				return
+			}
+		}
+	}
+}
+
+// DirPaths returns a sequence of directory paths, ignoring files.
+// See DirEntries.
+func (tr *Ranger) DirPaths() iter.Seq[string] {
+	return func(yield func(string) bool) {
+		for e := range tr.DirEntries() {
+			if !yield(tr.outputPath(e)) {
+				return
+			}
+		}
+	}
+}
+
+// Dirs returns a sequence of directory paths and their DirEntries,
+// ignoring files.
+// See DirEntries.
+func (tr *Ranger) Dirs() iter.Seq2[string, fs.DirEntry] {
+	return func(yield func(string, fs.DirEntry) bool) {
+		for e := range tr.DirEntries() {
+			if !yield(tr.outputPath(e), e.DirEntry) {
 				return
 			}
 		}
 	}
 }
+
+// SetPathRenderer tells the Ranger to pass each Entry
+// to render to produce the paths yielded by FilePaths and Paths,
+// such as to yield paths relative to the root or with forward slashes.
+// It is purely cosmetic: Entries and filters still see the walked paths.
+// Pass nil to yield Entry.Path unchanged, which is the default.
+func (tr *Ranger) SetPathRenderer(render func(Entry) string) {
+	tr.renderPath = render
+}
+
+// WithPathPrefix tells the Ranger to join prefix to the front of
+// every path yielded by FilePaths and Paths,
+// such as to present the paths from a walk of an fs.Sub
+// as paths in the original filesystem.
+// It is shorthand for SetPathRenderer and replaces any previous renderer.
+// Pass "" to remove the prefix, which is the default.
+func (tr *Ranger) WithPathPrefix(prefix string) {
+	if prefix == "" {
+		tr.SetPathRenderer(nil)
+		return
+	}
+	tr.SetPathRenderer(func(e Entry) string {
+		if e.useFilepath {
+			return filepath.Join(prefix, e.Path)
+		}
+		return path.Join(prefix, e.Path)
+	})
+}
+
+// outputPath returns the path of e as yielded by the path iterators.
+func (tr *Ranger) outputPath(e Entry) string {
+	if tr.renderPath == nil {
+		return e.Path
+	}
+	return tr.renderPath(e)
+}
+
+// RelPaths returns a sequence of the paths of matching files and directories
+// relative to the root, as reported by Entry.RelRoot,
+// so the root itself is ".".
+// Separators are those of the backend,
+// so paths from the OS filesystem use filepath.Separator.
+func (tr *Ranger) RelPaths() iter.Seq[string] {
+	return func(yield func(string) bool) {
+		for e := range tr.Entries() {
+			if !yield(e.RelRoot()) {
+				return
+			}
+		}
+	}
+}
+
+// RelEntries returns a sequence of pairs of Entry.RelRoot and Entry
+// for matching files and directories.
+func (tr *Ranger) RelEntries() iter.Seq2[string, Entry] {
+	return func(yield func(string, Entry) bool) {
+		for e := range tr.Entries() {
+			if !yield(e.RelRoot(), e) {
+				return
+			}
+		}
+	}
+}
+
+// Batches returns a sequence of slices of up to size matching files and directories.
+// Every batch is full except possibly the last.
+// Each batch is a newly allocated slice, so it may be retained after iterating.
+// A size less than 1 is treated as 1.
+func (tr *Ranger) Batches(size int) iter.Seq[[]Entry] {
+	size = max(size, 1)
+	return func(yield func([]Entry) bool) {
+		batch := make([]Entry, 0, size)
+		for e := range tr.Entries() {
+			batch = append(batch, e)
+			if len(batch) < size {
+				continue
+			}
+			if !yield(batch) {
+				return
+			}
+			batch = make([]Entry, 0, size)
+		}
+		if len(batch) > 0 {
+			yield(batch)
+		}
+	}
+}
+
+// Collect walks the tree and returns the matching files,
+// ignoring directories, along with the Ranger's Err().
+// See CollectEntries to include directories.
+func (tr *Ranger) Collect() ([]Entry, error) {
+	entries := slices.Collect(tr.FileEntries())
+	return entries, tr.Err()
+}
+
+// CollectEntries walks the tree and returns the matching files and directories,
+// along with the Ranger's Err().
+func (tr *Ranger) CollectEntries() ([]Entry, error) {
+	entries := slices.Collect(tr.Entries())
+	return entries, tr.Err()
+}
+
+// Walk returns a sequence of matching files and directories,
+// each paired with the error, if any,
+// returned by the IncludeErr or ExcludeErr filters for that entry.
+// Entries whose filters failed are yielded with the error
+// instead of being passed to the ErrorPolicy,
+// so the caller decides whether to include them,
+// and they do not set Err().
+// Errors from walking the tree itself are still handled by the ErrorPolicy.
+// Entries are yielded in walk order, ignoring SortedByModTime.
+func (tr *Ranger) Walk() iter.Seq2[Entry, error] {
+	return func(yield func(Entry, error) bool) {
+		tr.passFilterErrs = true
+		defer func() {
+			tr.passFilterErrs = false
+		}()
+		for e, included := range tr.visit {
+			if included && !yield(e, tr.yieldErr) {
+				return
+			}
+		}
+	}
+}
+
+// EntriesErr returns a sequence of matching files and directories,
+// each paired with the error, if any, encountered at that entry,
+// so the caller can handle each error where it happens,
+// such as by logging it, retrying, or breaking out of the loop to halt the walk.
+// Errors are yielded instead of being passed to the ErrorPolicy,
+// so the walk continues past every error unless the caller stops it.
+// Errors from walking the tree are yielded with the Entry they occurred at,
+// which for the root may have a nil DirEntry,
+// and a directory which cannot be read is yielded a second time with the error.
+// As with Walk, errors from IncludeErr and ExcludeErr filters
+// are yielded with the entry they were checking.
+// Yielded errors do not set Err(),
+// which only reports errors that stop the walk before an entry,
+// such as from MaxVisits or RequireRoot.
+// Entries are yielded in walk order, ignoring SortedByModTime.
+func (tr *Ranger) EntriesErr() iter.Seq2[Entry, error] {
+	return func(yield func(Entry, error) bool) {
+		tr.passWalkErrs = true
+		defer func() {
+			tr.passWalkErrs = false
+		}()
+		for e, err := range tr.Walk() {
+			if !yield(e, err) {
+				return
+			}
+		}
+	}
+}
+
+// WalkErr calls fn for each matching file and directory.
+// If fn returns an error, the walk stops
+// and WalkErr returns that error wrapped in a *CallbackError.
+// Otherwise, it returns the Ranger's Err(), if any,
+// wrapped in a *WalkError once the walk is done.
+func (tr *Ranger) WalkErr(fn func(Entry) error) error {
+	for e := range tr.Entries() {
+		if err := fn(e); err != nil {
+			return &CallbackError{e.Path, err}
+		}
+	}
+	return tr.walkError()
+}
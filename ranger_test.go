@@ -5,11 +5,14 @@ import (
 	"fmt"
 	"io/fs"
 	"os"
+	"path"
 	"path/filepath"
 	"slices"
 	"strings"
+	"sync"
 	"testing"
 	"testing/fstest"
+	"time"
 
 	"github.com/carlmjohnson/be"
 	"github.com/earthboundkid/walker"
@@ -171,6 +174,9 @@ func TestRanger_iter_break(t *testing.T) {
 	for range tr.FilePaths() {
 		break
 	}
+	for range tr.Paths() {
+		break
+	}
 }
 
 func ExampleRanger() {
@@ -266,6 +272,29 @@ func TestOnErrorHalt(t *testing.T) {
 	be.True(t, errors.Is(w.Err(), fs.ErrPermission))
 }
 
+func TestRanger_ErrPath(t *testing.T) {
+	dir := tempDirWithPermErr(t)
+
+	w := walker.New(nil, dir, walker.OnErrorHalt)
+	be.Equal(t, "", w.ErrPath())
+	for range w.FilePaths() {
+	}
+	be.True(t, errors.Is(w.Err(), fs.ErrPermission))
+	be.Equal(t, filepath.Join(dir, "2"), w.ErrPath())
+
+	w = walker.New(nil, dir, walker.OnErrorIgnore)
+	for range w.FilePaths() {
+	}
+	be.NilErr(t, w.Err())
+	be.Equal(t, "", w.ErrPath())
+
+	w = walker.New(nil, filepath.Join(dir, "missing"), walker.OnErrorHalt)
+	w.RequireRoot(true)
+	for range w.FilePaths() {
+	}
+	be.Equal(t, filepath.Join(dir, "missing"), w.ErrPath())
+}
+
 func TestOnErrPermissionIgnore(t *testing.T) {
 	dir := tempDirWithPermErr(t)
 
@@ -326,3 +355,1450 @@ func TestCollectErrors(t *testing.T) {
 	be.Equal(t, 1, len(errs))
 	be.True(t, errors.Is(errs[0], fs.ErrPermission))
 }
+
+func TestRanger_Trace(t *testing.T) {
+	testFS := fstest.MapFS{
+		"a.txt":                &fstest.MapFile{},
+		"dir1/file3.txt":       &fstest.MapFile{},
+		"dir1/file4.log":       &fstest.MapFile{},
+		"dir2/file5.txt":       &fstest.MapFile{},
+		"dir2/subdir/file6.go": &fstest.MapFile{},
+		"file1.txt":            &fstest.MapFile{},
+		"file2.log":            &fstest.MapFile{},
+	}
+
+	var buf strings.Builder
+	tr := walker.New(testFS, ".", walker.OnErrorHalt)
+	tr.Include(walker.MatchExtension(".txt"))
+	tr.ExcludeDir(walker.MatchRegexpMust("dir2"))
+	tr.Trace(&buf)
+	for range tr.Entries() {
+	}
+	be.Equal(t, `exclude . (include-files)
+include a.txt
+exclude dir1 (include-files)
+include dir1/file3.txt
+exclude dir1/file4.log (include-files)
+skip dir2 (exclude-dirs)
+include file1.txt
+exclude file2.log (include-files)
+`, buf.String())
+}
+
+func TestRanger_Range(t *testing.T) {
+	testFS := fstest.MapFS{
+		"a.txt":                &fstest.MapFile{},
+		"dir1/file3.txt":       &fstest.MapFile{},
+		"dir1/file4.txt":       &fstest.MapFile{},
+		"dir2/file5.txt":       &fstest.MapFile{},
+		"dir2/subdir/file6.go": &fstest.MapFile{},
+		"dir2-old/file7.txt":   &fstest.MapFile{},
+		"file1.txt":            &fstest.MapFile{},
+	}
+	all := walker.New(testFS, ".", walker.OnErrorHalt)
+	want := slices.Collect(all.Paths())
+
+	for _, split := range []string{"dir1/file4.txt", "dir2", "dir2/subdir", "dir2-old", "z"} {
+		var got []string
+		for _, r := range [][2]string{{"", split}, {split, ""}} {
+			tr := walker.New(testFS, ".", walker.OnErrorHalt)
+			tr.Range(r[0], r[1])
+			got = append(got, slices.Collect(tr.Paths())...)
+			be.NilErr(t, tr.Err())
+		}
+		be.Equal(t, strings.Join(want, "; "), strings.Join(got, "; "))
+	}
+
+	tr := walker.New(testFS, ".", walker.OnErrorHalt)
+	tr.Range("dir1/file4.txt", "dir2/subdir")
+	paths := slices.Collect(tr.FilePaths())
+	be.Equal(t, "dir1/file4.txt; dir2/file5.txt", strings.Join(paths, "; "))
+}
+
+func TestNewResume(t *testing.T) {
+	testFS := fstest.MapFS{
+		"a.txt":                &fstest.MapFile{},
+		"dir1/file3.txt":       &fstest.MapFile{},
+		"dir1/file4.log":       &fstest.MapFile{},
+		"dir1.txt":             &fstest.MapFile{},
+		"dir2/file5.txt":       &fstest.MapFile{},
+		"dir2/subdir/file6.go": &fstest.MapFile{},
+		"file1.txt":            &fstest.MapFile{},
+		"file2.log":            &fstest.MapFile{},
+	}
+	temp := t.TempDir()
+	be.NilErr(t, os.CopyFS(temp, testFS))
+	const want = "a.txt; dir1/file3.txt; dir1/file4.log; dir1.txt; dir2/file5.txt; dir2/subdir/file6.go; file1.txt; file2.log"
+
+	for _, tc := range []struct {
+		name string
+		fsys fs.FS
+		root string
+	}{
+		{"fs", testFS, "."},
+		{"os", nil, temp},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			for n := 1; n <= 8; n++ {
+				var paths []string
+				tr := walker.New(tc.fsys, tc.root, walker.OnErrorHalt)
+				for e := range tr.FileEntries() {
+					paths = append(paths, filepath.ToSlash(e.RelRoot()))
+					if len(paths) == n {
+						break
+					}
+				}
+				tr = walker.NewResume(tc.fsys, tc.root, walker.OnErrorHalt, tr.Cursor())
+				for e := range tr.FileEntries() {
+					paths = append(paths, filepath.ToSlash(e.RelRoot()))
+				}
+				be.NilErr(t, tr.Err())
+				be.Equal(t, want, strings.Join(paths, "; "))
+			}
+		})
+	}
+}
+
+func TestRanger_AddExclude(t *testing.T) {
+	testFS := fstest.MapFS{
+		"a.txt":                &fstest.MapFile{},
+		"dir1/file3.txt":       &fstest.MapFile{},
+		"dir1/file4.log":       &fstest.MapFile{},
+		"dir2/file5.txt":       &fstest.MapFile{},
+		"dir2/subdir/file6.go": &fstest.MapFile{},
+		"file1.txt":            &fstest.MapFile{},
+		"file2.log":            &fstest.MapFile{},
+	}
+	tr := walker.New(testFS, ".", walker.OnErrorHalt)
+	tr.AddExclude(walker.MatchExtension(".log"))
+	tr.AddExclude(walker.MatchExtension(".go"))
+	paths := slices.Collect(tr.FilePaths())
+	be.Equal(t, "a.txt; dir1/file3.txt; dir2/file5.txt; file1.txt", strings.Join(paths, "; "))
+
+	tr.AddExcludeDir(walker.MatchGlobName("dir1"))
+	tr.AddExcludeDir(walker.MatchGlobName("dir2"))
+	paths = slices.Collect(tr.FilePaths())
+	be.Equal(t, "a.txt; file1.txt", strings.Join(paths, "; "))
+
+	// Exclude still replaces
+	tr.Exclude(walker.MatchExtension(".log"))
+	paths = slices.Collect(tr.FilePaths())
+	be.Equal(t, "a.txt; file1.txt", strings.Join(paths, "; "))
+	tr.ExcludeDir(walker.MatchGlobName("dir1"))
+	paths = slices.Collect(tr.FilePaths())
+	be.Equal(t, "a.txt; dir2/file5.txt; dir2/subdir/file6.go; file1.txt", strings.Join(paths, "; "))
+}
+
+func TestRanger_AddInclude(t *testing.T) {
+	testFS := fstest.MapFS{
+		"a.txt":                &fstest.MapFile{},
+		"b.log":                &fstest.MapFile{},
+		"c.go":                 &fstest.MapFile{},
+		"dir1/file3.txt":       &fstest.MapFile{},
+		"dir2/file5.log":       &fstest.MapFile{},
+		"dir3/subdir/file6.go": &fstest.MapFile{},
+	}
+	tr := walker.New(testFS, ".", walker.OnErrorHalt)
+	for _, ext := range []string{".txt", ".log"} {
+		tr.AddInclude(walker.MatchExtension(ext))
+	}
+	paths := slices.Collect(tr.FilePaths())
+	be.Equal(t, "a.txt; b.log; dir1/file3.txt; dir2/file5.log", strings.Join(paths, "; "))
+
+	// Exclude filters win over include filters.
+	tr.AddExclude(walker.MatchGlobName("a.*"))
+	tr.AddExclude(walker.MatchGlobName("b.*"))
+	paths = slices.Collect(tr.FilePaths())
+	be.Equal(t, "dir1/file3.txt; dir2/file5.log", strings.Join(paths, "; "))
+
+	// Include replaces every previously added filter.
+	tr.Include(walker.MatchExtension(".go"))
+	paths = slices.Collect(tr.FilePaths())
+	be.Equal(t, "c.go; dir3/subdir/file6.go", strings.Join(paths, "; "))
+
+	tr = walker.New(testFS, ".", walker.OnErrorHalt)
+	tr.AddIncludeDir(walker.MatchGlobName("dir1"))
+	tr.AddIncludeDir(walker.MatchGlobName("dir2"))
+	tr.AddExcludeDir(walker.MatchGlobName("dir2"))
+	tr.Include(walker.MatchGlobName("file*"))
+	paths = slices.Collect(tr.FilePaths())
+	be.Equal(t, "dir1/file3.txt", strings.Join(paths, "; "))
+}
+
+func TestRanger_IncludeAll(t *testing.T) {
+	testFS := fstest.MapFS{
+		"a.go":           &fstest.MapFile{},
+		"a_test.go":      &fstest.MapFile{},
+		"dir1/b.go":      &fstest.MapFile{},
+		"dir1/b_test.go": &fstest.MapFile{},
+		"dir1/c.txt":     &fstest.MapFile{},
+	}
+	tr := walker.New(testFS, ".", walker.OnErrorHalt)
+	tr.IncludeAll(walker.MatchExtension(".go"))
+	tr.IncludeAll(walker.Not(walker.MatchGlobName("*_test.go")))
+	paths := slices.Collect(tr.FilePaths())
+	be.Equal(t, "a.go; dir1/b.go", strings.Join(paths, "; "))
+}
+
+func TestRanger_reuse(t *testing.T) {
+	testFS := fstest.MapFS{
+		"a.txt":                &fstest.MapFile{},
+		"dir1/file3.txt":       &fstest.MapFile{},
+		"dir2/subdir/file6.go": &fstest.MapFile{},
+	}
+	tr := walker.New(testFS, ".", walker.OnErrorHalt)
+	tr.Include(walker.MatchExtension(".txt", ".go"))
+	first := slices.Collect(tr.FilePaths())
+	be.NilErr(t, tr.Err())
+	second := slices.Collect(tr.FilePaths())
+	be.NilErr(t, tr.Err())
+	be.AllEqual(t, first, second)
+	be.Equal(t, "a.txt; dir1/file3.txt; dir2/subdir/file6.go", strings.Join(second, "; "))
+
+	// Walk state does not leak from a walk that was stopped early.
+	for e := range tr.FileEntries() {
+		if e.Path == "dir1/file3.txt" {
+			tr.SkipDir()
+			break
+		}
+	}
+	be.AllEqual(t, first, slices.Collect(tr.FilePaths()))
+
+	// Nor does an error from a previous walk.
+	testFS["bad"] = &fstest.MapFile{Mode: fs.ModeDir}
+	tr = walker.New(badDirFS{testFS}, ".", walker.OnErrorHalt)
+	_ = slices.Collect(tr.FilePaths())
+	be.Nonzero(t, tr.Err())
+	delete(testFS, "bad")
+	be.AllEqual(t, first, slices.Collect(tr.FilePaths()))
+	be.NilErr(t, tr.Err())
+}
+
+// badDirFS is an fs.FS which fails to read the directory named bad.
+type badDirFS struct {
+	fstest.MapFS
+}
+
+func (fsys badDirFS) ReadDir(name string) ([]fs.DirEntry, error) {
+	if name == "bad" {
+		return nil, fs.ErrPermission
+	}
+	return fsys.MapFS.ReadDir(name)
+}
+
+func TestRanger_Clone(t *testing.T) {
+	testFS := fstest.MapFS{
+		"a.txt":                &fstest.MapFile{},
+		"dir1/file3.txt":       &fstest.MapFile{},
+		"dir1/file4.log":       &fstest.MapFile{},
+		"dir2/file5.txt":       &fstest.MapFile{},
+		"dir2/subdir/file6.go": &fstest.MapFile{},
+		"file1.txt":            &fstest.MapFile{},
+		"file2.log":            &fstest.MapFile{},
+	}
+	tr := walker.New(testFS, ".", walker.OnErrorHalt)
+	tr.Include(walker.MatchExtension(".txt"))
+
+	results := make(chan string)
+	for range 10 {
+		go func() {
+			c := tr.Clone()
+			paths := slices.Collect(c.FilePaths())
+			results <- strings.Join(paths, "; ")
+		}()
+	}
+	for range 10 {
+		be.Equal(t, "a.txt; dir1/file3.txt; dir2/file5.txt; file1.txt", <-results)
+	}
+}
+
+func TestRanger_missingRoot(t *testing.T) {
+	missing := filepath.Join(t.TempDir(), "does-not-exist")
+	var errs []error
+	for _, tc := range []struct {
+		name string
+		erp  walker.ErrorPolicy
+	}{
+		{"halt", walker.OnErrorHalt},
+		{"ignore", walker.OnErrorIgnore},
+		{"permission ignore", walker.OnErrPermissionIgnore},
+		{"collect", walker.OnErrorCollect(&errs)},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			tr := walker.New(nil, missing, tc.erp)
+			err := tr.Validate()
+			be.True(t, errors.Is(err, fs.ErrNotExist))
+			be.In(t, "does-not-exist", err.Error())
+
+			paths := slices.Collect(tr.FilePaths())
+			be.Equal(t, 0, len(paths))
+			be.True(t, errors.Is(tr.Err(), fs.ErrNotExist))
+		})
+	}
+	be.Equal(t, 1, len(errs))
+
+	tr := walker.New(nil, missing, walker.OnErrorPanic)
+	p := try(func() {
+		for range tr.FilePaths() {
+		}
+	})
+	be.True(t, errors.Is(p.(error), fs.ErrNotExist))
+
+	tr = walker.New(fstest.MapFS{}, "missing", walker.OnErrorHalt)
+	be.True(t, errors.Is(tr.Validate(), fs.ErrNotExist))
+	tr = walker.New(nil, t.TempDir(), walker.OnErrorHalt)
+	be.NilErr(t, tr.Validate())
+}
+
+func TestRanger_SampleDirs(t *testing.T) {
+	testFS := fstest.MapFS{
+		"a.txt":                &fstest.MapFile{},
+		"dir1/file3.txt":       &fstest.MapFile{},
+		"dir1/file4.log":       &fstest.MapFile{},
+		"dir2/file5.txt":       &fstest.MapFile{},
+		"dir2/subdir/file6.go": &fstest.MapFile{},
+		"file1.txt":            &fstest.MapFile{},
+		"file2.log":            &fstest.MapFile{},
+	}
+	for n, want := range []string{
+		"a.txt; dir1/file3.txt; dir1/file4.log; dir2/file5.txt; dir2/subdir/file6.go; file1.txt; file2.log",
+		"a.txt; dir1/file3.txt; dir1/file4.log; file1.txt; file2.log",
+		"a.txt; dir1/file3.txt; dir1/file4.log; dir2/file5.txt; file1.txt; file2.log",
+		"a.txt; dir1/file3.txt; dir1/file4.log; dir2/file5.txt; dir2/subdir/file6.go; file1.txt; file2.log",
+	} {
+		tr := walker.New(testFS, ".", walker.OnErrorHalt)
+		tr.SampleDirs(n)
+		paths := slices.Collect(tr.FilePaths())
+		be.Equal(t, want, strings.Join(paths, "; "))
+		// Sampling is deterministic across walks
+		paths = slices.Collect(tr.FilePaths())
+		be.Equal(t, want, strings.Join(paths, "; "))
+	}
+}
+
+func TestRanger_SortedByModTime(t *testing.T) {
+	now := time.Now()
+	testFS := fstest.MapFS{
+		"a.txt":          &fstest.MapFile{ModTime: now.Add(-2 * time.Hour)},
+		"dir1/file3.txt": &fstest.MapFile{ModTime: now.Add(-1 * time.Hour)},
+		"dir1/file4.log": &fstest.MapFile{ModTime: now.Add(-3 * time.Hour)},
+		"file1.txt":      &fstest.MapFile{ModTime: now},
+		"file2.log":      &fstest.MapFile{ModTime: now.Add(-4 * time.Hour)},
+	}
+	tr := walker.New(testFS, ".", walker.OnErrorHalt)
+	tr.SortedByModTime(false)
+	paths := slices.Collect(tr.FilePaths())
+	be.NilErr(t, tr.Err())
+	be.Equal(t, "file2.log; dir1/file4.log; a.txt; dir1/file3.txt; file1.txt", strings.Join(paths, "; "))
+
+	tr.SortedByModTime(true)
+	paths = slices.Collect(tr.FilePaths())
+	be.Equal(t, "file1.txt; dir1/file3.txt; a.txt; dir1/file4.log; file2.log", strings.Join(paths, "; "))
+}
+
+func TestRanger_SizeBudget(t *testing.T) {
+	testFS := fstest.MapFS{
+		"a.txt":          &fstest.MapFile{Data: make([]byte, 40)},
+		"dir1/file3.txt": &fstest.MapFile{Data: make([]byte, 40)},
+		"dir1/file4.log": &fstest.MapFile{Data: make([]byte, 40)},
+		"file1.txt":      &fstest.MapFile{Data: make([]byte, 40)},
+	}
+	tr := walker.New(testFS, ".", walker.OnErrorHalt)
+	tr.SizeBudget(100, false)
+	paths := slices.Collect(tr.FilePaths())
+	be.NilErr(t, tr.Err())
+	be.Equal(t, "a.txt; dir1/file3.txt", strings.Join(paths, "; "))
+
+	tr.SizeBudget(100, true)
+	paths = slices.Collect(tr.FilePaths())
+	be.Equal(t, "a.txt; dir1/file3.txt; dir1/file4.log", strings.Join(paths, "; "))
+
+	tr.SizeBudget(80, false)
+	paths = slices.Collect(tr.FilePaths())
+	be.Equal(t, "a.txt; dir1/file3.txt", strings.Join(paths, "; "))
+
+	tr.SizeBudget(0, false)
+	paths = slices.Collect(tr.FilePaths())
+	be.Equal(t, "a.txt; dir1/file3.txt; dir1/file4.log; file1.txt", strings.Join(paths, "; "))
+}
+
+func TestRanger_ExcludeHidden(t *testing.T) {
+	testFS := fstest.MapFS{
+		".env":           &fstest.MapFile{},
+		".git/config":    &fstest.MapFile{},
+		"a.txt":          &fstest.MapFile{},
+		"dir1/file3.txt": &fstest.MapFile{},
+	}
+	tr := walker.New(testFS, ".", walker.OnErrorHalt)
+	tr.ExcludeHidden()
+	var paths []string
+	for e := range tr.Entries() {
+		paths = append(paths, e.Path)
+	}
+	be.NilErr(t, tr.Err())
+	// The root "." is not hidden.
+	be.Equal(t, ".; a.txt; dir1; dir1/file3.txt", strings.Join(paths, "; "))
+	be.Equal(t, ".; dir1", strings.Join(slices.Collect(tr.DirPaths()), "; "))
+}
+
+func TestRanger_IncludeErr(t *testing.T) {
+	testFS := fstest.MapFS{
+		"a.txt":          &fstest.MapFile{Data: []byte("TODO: a")},
+		"dir1/file3.txt": &fstest.MapFile{Data: []byte("nothing")},
+		"dir1/file4.txt": &fstest.MapFile{Data: []byte("TODO: b")},
+		"file1.txt":      &fstest.MapFile{Data: []byte("TODO: c")},
+	}
+	errUnreadable := errors.New("unreadable")
+	containsTODO := func(e walker.Entry) (bool, error) {
+		if e.IsDir() {
+			return false, nil
+		}
+		if e.Path == "dir1/file4.txt" {
+			return false, errUnreadable
+		}
+		data, err := fs.ReadFile(testFS, e.Path)
+		return strings.Contains(string(data), "TODO"), err
+	}
+
+	var errs []error
+	tr := walker.New(testFS, ".", walker.OnErrorCollect(&errs))
+	tr.Include(walker.Not(walker.MatchGlobName("file1.txt")))
+	tr.IncludeErr(containsTODO)
+	paths := slices.Collect(tr.FilePaths())
+	be.Equal(t, "a.txt", strings.Join(paths, "; "))
+	be.Equal(t, 1, len(errs))
+	be.True(t, errors.Is(errs[0], errUnreadable))
+
+	tr = walker.New(testFS, ".", walker.OnErrorHalt)
+	tr.IncludeErr(containsTODO)
+	paths = slices.Collect(tr.FilePaths())
+	be.Equal(t, "a.txt", strings.Join(paths, "; "))
+	be.True(t, errors.Is(tr.Err(), errUnreadable))
+
+	tr = walker.New(testFS, ".", walker.OnErrorIgnore)
+	tr.ExcludeErr(containsTODO)
+	paths = slices.Collect(tr.FilePaths())
+	be.Equal(t, "dir1/file3.txt", strings.Join(paths, "; "))
+}
+
+func TestRanger_Walk(t *testing.T) {
+	testFS := fstest.MapFS{
+		"a.txt":          &fstest.MapFile{Data: []byte("TODO: a")},
+		"dir1/file3.txt": &fstest.MapFile{Data: []byte("nothing")},
+		"dir1/file4.txt": &fstest.MapFile{Data: []byte("TODO: b")},
+	}
+	errUnreadable := errors.New("unreadable")
+	containsTODO := func(e walker.Entry) (bool, error) {
+		if e.IsDir() {
+			return true, nil
+		}
+		if e.Path == "dir1/file4.txt" {
+			return false, errUnreadable
+		}
+		data, err := fs.ReadFile(testFS, e.Path)
+		return strings.Contains(string(data), "TODO"), err
+	}
+
+	tr := walker.New(testFS, ".", walker.OnErrorHalt)
+	tr.IncludeErr(containsTODO)
+	var got []string
+	for e, err := range tr.Walk() {
+		if err != nil {
+			be.True(t, errors.Is(err, errUnreadable))
+			got = append(got, e.Path+" (error)")
+			continue
+		}
+		got = append(got, e.Path)
+	}
+	be.Equal(t, ".; a.txt; dir1; dir1/file4.txt (error)", strings.Join(got, "; "))
+	be.NilErr(t, tr.Err())
+
+	// The ErrorPolicy still applies to other iterators.
+	paths := slices.Collect(tr.Paths())
+	be.Equal(t, ".; a.txt; dir1", strings.Join(paths, "; "))
+	be.True(t, errors.Is(tr.Err(), errUnreadable))
+}
+
+func TestRanger_EntriesErr(t *testing.T) {
+	dir := tempDirWithPermErr(t)
+
+	tr := walker.New(nil, dir, walker.OnErrorHalt)
+	var got []string
+	for e, err := range tr.EntriesErr() {
+		rel := filepath.ToSlash(e.RelRoot())
+		if err != nil {
+			be.True(t, errors.Is(err, fs.ErrPermission))
+			rel += " (error)"
+		}
+		got = append(got, rel)
+	}
+	be.Equal(t, ".; 1.txt; 2; 2 (error); 3.txt", strings.Join(got, "; "))
+	be.NilErr(t, tr.Err())
+
+	// Breaking out of the loop stops the walk.
+	got = nil
+	for e, err := range tr.EntriesErr() {
+		if err != nil {
+			break
+		}
+		got = append(got, filepath.ToSlash(e.RelRoot()))
+	}
+	be.Equal(t, ".; 1.txt; 2", strings.Join(got, "; "))
+	be.NilErr(t, tr.Err())
+
+	// The ErrorPolicy still applies to other iterators.
+	paths := slices.Collect(tr.FilePaths())
+	be.Equal(t, 1, len(paths))
+	be.True(t, errors.Is(tr.Err(), fs.ErrPermission))
+
+	tr = walker.New(nil, filepath.Join(dir, "missing"), walker.OnErrorHalt)
+	got = nil
+	for e, err := range tr.EntriesErr() {
+		be.True(t, errors.Is(err, fs.ErrNotExist))
+		be.Equal(t, nil, e.DirEntry)
+		got = append(got, filepath.Base(e.Path))
+	}
+	be.Equal(t, "missing", strings.Join(got, "; "))
+}
+
+func TestRanger_Paths(t *testing.T) {
+	testFS := fstest.MapFS{
+		"a.txt":                &fstest.MapFile{},
+		"dir1/file3.txt":       &fstest.MapFile{},
+		"dir2/subdir/file6.go": &fstest.MapFile{},
+	}
+	tr := walker.New(testFS, ".", walker.OnErrorHalt)
+	paths := slices.Collect(tr.Paths())
+	be.NilErr(t, tr.Err())
+	be.Equal(t, ". a.txt dir1 dir1/file3.txt dir2 dir2/subdir dir2/subdir/file6.go", strings.Join(paths, " "))
+
+	tr.ExcludeDir(walker.MatchGlobName("subdir"))
+	paths = slices.Collect(tr.Paths())
+	be.Equal(t, ". a.txt dir1 dir1/file3.txt dir2", strings.Join(paths, " "))
+}
+
+func TestRanger_MaxVisits(t *testing.T) {
+	testFS := fstest.MapFS{
+		"a.txt":                &fstest.MapFile{},
+		"dir1/file3.txt":       &fstest.MapFile{},
+		"dir1/file4.txt":       &fstest.MapFile{},
+		"dir2/subdir/file6.go": &fstest.MapFile{},
+		"file1.txt":            &fstest.MapFile{},
+	}
+	tr := walker.New(testFS, ".", walker.OnErrorIgnore)
+	tr.MaxVisits(5)
+	tr.Exclude(walker.MatchExtension(".txt"))
+	paths := slices.Collect(tr.Paths())
+	be.True(t, errors.Is(tr.Err(), walker.ErrMaxVisitsExceeded))
+	be.Equal(t, ". dir1", strings.Join(paths, " "))
+
+	// A cap of the exact tree size is not exceeded.
+	tr.MaxVisits(9)
+	paths = slices.Collect(tr.Paths())
+	be.NilErr(t, tr.Err())
+	be.Equal(t, ". dir1 dir2 dir2/subdir dir2/subdir/file6.go", strings.Join(paths, " "))
+
+	tr.MaxVisits(8)
+	paths = slices.Collect(tr.Paths())
+	be.True(t, errors.Is(tr.Err(), walker.ErrMaxVisitsExceeded))
+	be.Equal(t, ". dir1 dir2 dir2/subdir dir2/subdir/file6.go", strings.Join(paths, " "))
+
+	tr.MaxVisits(0)
+	paths = slices.Collect(tr.Paths())
+	be.NilErr(t, tr.Err())
+	be.Equal(t, 5, len(paths))
+}
+
+func TestRanger_IncludeDirsInOutput(t *testing.T) {
+	testFS := fstest.MapFS{
+		"a.txt":                &fstest.MapFile{},
+		"dir1/file3.txt":       &fstest.MapFile{},
+		"dir2/subdir/file6.go": &fstest.MapFile{},
+		"dir2/file5.txt":       &fstest.MapFile{},
+	}
+	tr := walker.New(testFS, ".", walker.OnErrorHalt)
+	tr.IncludeDirsInOutput(false)
+	tr.ExcludeDir(walker.MatchGlobName("subdir"))
+	var paths []string
+	for e := range tr.Entries() {
+		paths = append(paths, e.Path)
+	}
+	be.NilErr(t, tr.Err())
+	be.Equal(t, "a.txt dir1/file3.txt dir2/file5.txt", strings.Join(paths, " "))
+
+	tr.SortedByModTime(false)
+	paths = slices.Collect(tr.Paths())
+	be.Equal(t, "a.txt dir1/file3.txt dir2/file5.txt", strings.Join(paths, " "))
+
+	tr.IncludeDirsInOutput(true)
+	paths = slices.Collect(tr.Paths())
+	be.Equal(t, ". a.txt dir1 dir1/file3.txt dir2 dir2/file5.txt", strings.Join(paths, " "))
+}
+
+func TestRanger_IncludePath(t *testing.T) {
+	testFS := fstest.MapFS{
+		"a.txt":                &fstest.MapFile{},
+		"dir1/file3.txt":       &fstest.MapFile{},
+		"dir1/file4.log":       &fstest.MapFile{},
+		"dir1/sub/file7.txt":   &fstest.MapFile{},
+		"dir2/file5.txt":       &fstest.MapFile{},
+		"dir2/subdir/file6.go": &fstest.MapFile{},
+	}
+	tr := walker.New(testFS, ".", walker.OnErrorHalt)
+	var trace strings.Builder
+	tr.Trace(&trace)
+	tr.IncludePath("dir1/*.txt")
+	paths := slices.Collect(tr.FilePaths())
+	be.NilErr(t, tr.Err())
+	be.Equal(t, "dir1/file3.txt", strings.Join(paths, "; "))
+	be.In(t, "skip dir2 (include-dirs)", trace.String())
+	be.In(t, "skip dir1/sub (include-dirs)", trace.String())
+	be.False(t, strings.Contains(trace.String(), "file5.txt"))
+
+	tr = walker.New(testFS, ".", walker.OnErrorHalt)
+	tr.IncludePath("dir1/*.txt", "*/subdir/*.go", "*.txt")
+	paths = slices.Collect(tr.FilePaths())
+	be.Equal(t, "a.txt; dir1/file3.txt; dir2/subdir/file6.go", strings.Join(paths, "; "))
+
+	tr = walker.New(testFS, ".", walker.OnErrorHalt)
+	tr.IncludePath("*.txt")
+	paths = slices.Collect(tr.FilePaths())
+	be.Equal(t, "a.txt", strings.Join(paths, "; "))
+}
+
+func TestRanger_OnlyUnder(t *testing.T) {
+	testFS := fstest.MapFS{
+		"a.txt":                &fstest.MapFile{},
+		"dir1/file3.txt":       &fstest.MapFile{},
+		"dir1/sub/file7.txt":   &fstest.MapFile{},
+		"dir2/file5.txt":       &fstest.MapFile{},
+		"dir2/subdir/file6.go": &fstest.MapFile{},
+		"dir3/a/file8.txt":     &fstest.MapFile{},
+		"dir3/b/file9.txt":     &fstest.MapFile{},
+		"dir3/file10.txt":      &fstest.MapFile{},
+	}
+	tr := walker.New(testFS, ".", walker.OnErrorHalt)
+	var trace strings.Builder
+	tr.Trace(&trace)
+	tr.OnlyUnder("dir1")
+	paths := slices.Collect(tr.FilePaths())
+	be.NilErr(t, tr.Err())
+	be.Equal(t, "dir1/file3.txt; dir1/sub/file7.txt", strings.Join(paths, "; "))
+	be.In(t, "skip dir2 (include-dirs)", trace.String())
+	be.False(t, strings.Contains(trace.String(), "dir2/"))
+
+	tr = walker.New(testFS, ".", walker.OnErrorHalt)
+	tr.OnlyUnder("dir1/sub", "dir3/b/")
+	paths = slices.Collect(tr.FilePaths())
+	be.NilErr(t, tr.Err())
+	be.Equal(t, "dir1/sub/file7.txt; dir3/b/file9.txt", strings.Join(paths, "; "))
+
+	// OnlyUnder narrows earlier include filters.
+	testFS["dir1/x.go"] = &fstest.MapFile{}
+	testFS["dir2/y.go"] = &fstest.MapFile{}
+	tr = walker.New(testFS, ".", walker.OnErrorHalt)
+	tr.Include(walker.MatchExtension(".go"))
+	tr.IncludeDir(func(e walker.Entry) bool {
+		return !e.IsDir() || e.Name() != "sub"
+	})
+	tr.OnlyUnder("dir1", "dir2/subdir")
+	paths = slices.Collect(tr.Paths())
+	be.NilErr(t, tr.Err())
+	be.Equal(t, "dir1/x.go; dir2/subdir/file6.go", strings.Join(paths, "; "))
+	be.Equal(t, ".; dir1; dir2; dir2/subdir", strings.Join(slices.Collect(tr.DirPaths()), "; "))
+}
+
+func TestRanger_DirEntries(t *testing.T) {
+	testFS := fstest.MapFS{
+		"a.txt":                &fstest.MapFile{},
+		"dir1/file3.txt":       &fstest.MapFile{},
+		"dir2/file5.txt":       &fstest.MapFile{},
+		"dir2/subdir/file6.go": &fstest.MapFile{},
+		"skip/sub/file7.txt":   &fstest.MapFile{},
+	}
+	tr := walker.New(testFS, ".", walker.OnErrorHalt)
+	tr.Include(walker.MatchExtension(".go"))
+	tr.ExcludeDir(walker.MatchGlobName("skip"))
+	paths := slices.Collect(tr.DirPaths())
+	be.NilErr(t, tr.Err())
+	be.Equal(t, ".; dir1; dir2; dir2/subdir", strings.Join(paths, "; "))
+
+	var names []string
+	for path, d := range tr.Dirs() {
+		be.True(t, d.IsDir())
+		names = append(names, path+":"+d.Name())
+	}
+	be.Equal(t, ".:.; dir1:dir1; dir2:dir2; dir2/subdir:subdir", strings.Join(names, "; "))
+
+	for e := range tr.DirEntries() {
+		be.True(t, e.IsDir())
+		if e.Path == "dir2" {
+			break
+		}
+	}
+	be.NilErr(t, tr.Err())
+
+	tr.IncludeDir(walker.MatchGlobName("dir*"))
+	paths = slices.Collect(tr.DirPaths())
+	be.Equal(t, "dir1; dir2", strings.Join(paths, "; "))
+}
+
+func TestRanger_WithPathPrefix(t *testing.T) {
+	testFS := fstest.MapFS{
+		"site/a.txt":                &fstest.MapFile{},
+		"site/dir1/file3.txt":       &fstest.MapFile{},
+		"site/dir2/subdir/file6.go": &fstest.MapFile{},
+	}
+	sub, err := fs.Sub(testFS, "site")
+	be.NilErr(t, err)
+	tr := walker.New(sub, ".", walker.OnErrorHalt)
+	tr.WithPathPrefix("site")
+	paths := slices.Collect(tr.Paths())
+	be.NilErr(t, tr.Err())
+	be.Equal(t, "site site/a.txt site/dir1 site/dir1/file3.txt site/dir2 site/dir2/subdir site/dir2/subdir/file6.go",
+		strings.Join(paths, " "))
+	for _, p := range paths {
+		_, err := fs.Stat(testFS, p)
+		be.NilErr(t, err)
+	}
+
+	paths = slices.Collect(tr.FilePaths())
+	be.Equal(t, "site/a.txt site/dir1/file3.txt site/dir2/subdir/file6.go", strings.Join(paths, " "))
+
+	// Entries are not affected.
+	for e := range tr.Entries() {
+		be.False(t, strings.HasPrefix(e.Path, "site"))
+	}
+}
+
+func TestRanger_SetPathRenderer(t *testing.T) {
+	testFS := fstest.MapFS{
+		"a.txt":                &fstest.MapFile{},
+		"dir1/file3.txt":       &fstest.MapFile{},
+		"dir2/subdir/file6.go": &fstest.MapFile{},
+	}
+	temp := t.TempDir()
+	be.NilErr(t, os.CopyFS(temp, testFS))
+	tr := walker.New(nil, temp, walker.OnErrorHalt)
+	tr.SetPathRenderer(func(e walker.Entry) string {
+		return filepath.ToSlash(e.RelRoot())
+	})
+	paths := slices.Collect(tr.Paths())
+	be.NilErr(t, tr.Err())
+	be.Equal(t, ". a.txt dir1 dir1/file3.txt dir2 dir2/subdir dir2/subdir/file6.go", strings.Join(paths, " "))
+
+	paths = slices.Collect(tr.FilePaths())
+	be.Equal(t, "a.txt dir1/file3.txt dir2/subdir/file6.go", strings.Join(paths, " "))
+
+	tr.SetPathRenderer(nil)
+	for p := range tr.FilePaths() {
+		be.True(t, strings.HasPrefix(p, temp))
+	}
+}
+
+func TestRanger_AbsolutePaths(t *testing.T) {
+	temp := t.TempDir()
+	testFS := fstest.MapFS{
+		"testdata/a.txt":         &fstest.MapFile{},
+		"testdata/dir1/file.txt": &fstest.MapFile{},
+	}
+	be.NilErr(t, os.CopyFS(temp, testFS))
+	wd, err := os.Getwd()
+	be.NilErr(t, err)
+	be.NilErr(t, os.Chdir(temp))
+	t.Cleanup(func() { be.NilErr(t, os.Chdir(wd)) })
+	abs, err := os.Getwd()
+	be.NilErr(t, err)
+
+	tr := walker.New(nil, "testdata", walker.OnErrorHalt)
+	tr.Include(walker.MatchGlobPath("testdata/dir1/*"))
+	tr.AbsolutePaths(true)
+	paths := slices.Collect(tr.FilePaths())
+	be.NilErr(t, tr.Err())
+	be.Equal(t, filepath.Join(abs, "testdata", "dir1", "file.txt"), strings.Join(paths, "; "))
+	for p := range tr.Paths() {
+		be.True(t, filepath.IsAbs(p))
+	}
+
+	tr.AbsolutePaths(false)
+	paths = slices.Collect(tr.FilePaths())
+	be.Equal(t, filepath.Join("testdata", "dir1", "file.txt"), strings.Join(paths, "; "))
+
+	fsTR := walker.New(testFS, "testdata", walker.OnErrorHalt)
+	fsTR.AbsolutePaths(true)
+	paths = slices.Collect(fsTR.FilePaths())
+	be.Equal(t, "testdata/a.txt; testdata/dir1/file.txt", strings.Join(paths, "; "))
+}
+
+func TestRanger_ExcludePaths(t *testing.T) {
+	testFS := fstest.MapFS{
+		"a.txt":                &fstest.MapFile{},
+		"dir1/file3.txt":       &fstest.MapFile{},
+		"dir1/file4.log":       &fstest.MapFile{},
+		"dir2/file5.txt":       &fstest.MapFile{},
+		"dir2/subdir/file6.go": &fstest.MapFile{},
+		"file1.txt":            &fstest.MapFile{},
+	}
+	temp := t.TempDir()
+	be.NilErr(t, os.CopyFS(temp, testFS))
+
+	for _, tc := range []struct {
+		tr      walker.Ranger
+		exclude []string
+	}{
+		{walker.New(testFS, ".", walker.OnErrorHalt),
+			[]string{"dir1/file4.log", "file1.txt", "dir2/subdir"}},
+		{walker.New(nil, temp, walker.OnErrorHalt),
+			[]string{filepath.Join(temp, "dir1", "file4.log"), "file1.txt", "dir2/subdir/"}},
+	} {
+		tc.tr.ExcludePaths(tc.exclude...)
+		var paths []string
+		for e := range tc.tr.FileEntries() {
+			paths = append(paths, filepath.ToSlash(e.RelRoot()))
+		}
+		be.NilErr(t, tc.tr.Err())
+		be.Equal(t, "a.txt; dir1/file3.txt; dir2/file5.txt", strings.Join(paths, "; "))
+	}
+}
+
+func TestRanger_Collect(t *testing.T) {
+	testFS := fstest.MapFS{
+		"a.txt":                &fstest.MapFile{},
+		"dir1/file3.txt":       &fstest.MapFile{},
+		"dir2/subdir/file6.go": &fstest.MapFile{},
+	}
+	tr := walker.New(testFS, ".", walker.OnErrorHalt)
+	pathsOf := func(entries []walker.Entry) string {
+		var paths []string
+		for _, e := range entries {
+			paths = append(paths, e.Path)
+		}
+		return strings.Join(paths, "; ")
+	}
+
+	files, err := tr.Collect()
+	be.NilErr(t, err)
+	be.Equal(t, "a.txt; dir1/file3.txt; dir2/subdir/file6.go", pathsOf(files))
+
+	entries, err := tr.CollectEntries()
+	be.NilErr(t, err)
+	be.Equal(t, ".; a.txt; dir1; dir1/file3.txt; dir2; dir2/subdir; dir2/subdir/file6.go", pathsOf(entries))
+
+	tr = walker.New(testFS, "missing", walker.OnErrorHalt)
+	files, err = tr.Collect()
+	be.Nonzero(t, err)
+	be.Equal(t, 0, len(files))
+}
+
+func TestRanger_CaseFoldDedup(t *testing.T) {
+	// Simulate a case-insensitive filesystem
+	// reached through paths with different casings.
+	testFS := fstest.MapFS{
+		"Dir/a.txt":  &fstest.MapFile{},
+		"README.md":  &fstest.MapFile{},
+		"dir/a.txt":  &fstest.MapFile{},
+		"dir/b.txt":  &fstest.MapFile{},
+		"readme.md":  &fstest.MapFile{},
+		"readme.txt": &fstest.MapFile{},
+	}
+	tr := walker.New(testFS, ".", walker.OnErrorHalt)
+	paths := slices.Collect(tr.Paths())
+	be.Equal(t, 9, len(paths))
+
+	tr.CaseFoldDedup()
+	for range 2 {
+		paths = slices.Collect(tr.Paths())
+		be.NilErr(t, tr.Err())
+		be.Equal(t, ".; Dir; Dir/a.txt; README.md; readme.txt", strings.Join(paths, "; "))
+	}
+}
+
+func TestRanger_nestedWalk(t *testing.T) {
+	testFS := fstest.MapFS{
+		"a.txt":          &fstest.MapFile{},
+		"dir1/file3.txt": &fstest.MapFile{},
+	}
+	tr := walker.New(testFS, ".", walker.OnErrorHalt)
+	p := try(func() {
+		for range tr.Entries() {
+			for range tr.FilePaths() {
+			}
+		}
+	})
+	msg, _ := p.(string)
+	be.In(t, "already walking", msg)
+	be.In(t, "Clone", msg)
+
+	// The Ranger is usable again after the panic.
+	be.Equal(t, 2, len(slices.Collect(tr.FilePaths())))
+
+	// Clone allows nested iteration.
+	var n int
+	for range tr.Entries() {
+		inner := tr.Clone()
+		for range inner.FilePaths() {
+			n++
+		}
+	}
+	be.Equal(t, 8, n)
+}
+
+func TestRanger_WalkErr(t *testing.T) {
+	testFS := fstest.MapFS{
+		"a.txt":          &fstest.MapFile{},
+		"dir1/file3.txt": &fstest.MapFile{},
+		"dir1/file4.log": &fstest.MapFile{},
+	}
+	tr := walker.New(testFS, ".", walker.OnErrorHalt)
+	var paths []string
+	err := tr.WalkErr(func(e walker.Entry) error {
+		paths = append(paths, e.Path)
+		return nil
+	})
+	be.NilErr(t, err)
+	be.Equal(t, ".; a.txt; dir1; dir1/file3.txt; dir1/file4.log", strings.Join(paths, "; "))
+
+	errStop := errors.New("stop")
+	paths = nil
+	err = tr.WalkErr(func(e walker.Entry) error {
+		paths = append(paths, e.Path)
+		if e.Path == "dir1" {
+			return errStop
+		}
+		return nil
+	})
+	be.True(t, errors.Is(err, errStop))
+	be.Equal(t, ".; a.txt; dir1", strings.Join(paths, "; "))
+	var cbErr *walker.CallbackError
+	be.True(t, errors.As(err, &cbErr))
+	be.Equal(t, "dir1", cbErr.Path)
+	var walkErr *walker.WalkError
+	be.False(t, errors.As(err, &walkErr))
+
+	tr = walker.New(testFS, "missing", walker.OnErrorHalt)
+	err = tr.WalkErr(func(walker.Entry) error { return nil })
+	be.True(t, errors.Is(err, fs.ErrNotExist))
+	be.True(t, errors.As(err, &walkErr))
+	be.False(t, errors.As(err, &cbErr))
+	be.Equal(t, "walker: open missing: file does not exist", err.Error())
+
+	// Errors from the walker package are not prefixed twice.
+	tr.RequireRoot(true)
+	err = tr.WalkErr(func(walker.Entry) error { return nil })
+	be.True(t, errors.As(err, &walkErr))
+	be.Equal(t, `walker: invalid root "missing": open missing: file does not exist`, err.Error())
+
+	tr = walker.New(testFS, ".", walker.OnErrorHalt)
+	tr.MaxVisits(1)
+	err = tr.WalkErr(func(walker.Entry) error { return nil })
+	be.True(t, errors.Is(err, walker.ErrMaxVisitsExceeded))
+	be.Equal(t, walker.ErrMaxVisitsExceeded.Error(), err.Error())
+}
+
+func TestRanger_WalkErr_permission(t *testing.T) {
+	dir := tempDirWithPermErr(t)
+	tr := walker.New(nil, dir, walker.OnErrorHalt)
+	err := tr.WalkErr(func(walker.Entry) error { return nil })
+	var walkErr *walker.WalkError
+	be.True(t, errors.As(err, &walkErr))
+	be.True(t, errors.Is(err, fs.ErrPermission))
+	var cbErr *walker.CallbackError
+	be.False(t, errors.As(err, &cbErr))
+
+	errStop := errors.New("stop")
+	err = tr.WalkErr(func(walker.Entry) error { return errStop })
+	be.True(t, errors.As(err, &cbErr))
+	be.False(t, errors.As(err, &walkErr))
+}
+
+func TestExpandRoot(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	t.Setenv("USERPROFILE", home)
+	t.Setenv("WALKER_LOGS", "logs")
+
+	for _, tc := range []struct {
+		root, want string
+	}{
+		{"~", home},
+		{"~/Documents", home + "/Documents"},
+		{"$HOME/logs", home + "/logs"},
+		{"/var/${WALKER_LOGS}/app", "/var/logs/app"},
+		{"dir/~file", "dir/~file"},
+		{"~user/file", "~user/file"},
+		{"testdata", "testdata"},
+	} {
+		got, err := walker.ExpandRoot(tc.root)
+		be.NilErr(t, err)
+		be.Equal(t, tc.want, got)
+	}
+
+	_, err := walker.ExpandRoot("$WALKER_UNSET_VARIABLE/logs")
+	be.Nonzero(t, err)
+
+	t.Setenv("HOME", "")
+	t.Setenv("USERPROFILE", "")
+	_, err = walker.ExpandRoot("~/Documents")
+	be.Nonzero(t, err)
+	got, err := walker.ExpandRoot("testdata")
+	be.NilErr(t, err)
+	be.Equal(t, "testdata", got)
+}
+
+// followFS simulates an fs.FS which transparently follows symbolic links,
+// reporting the links as directories.
+type followFS struct {
+	fstest.MapFS
+	links map[string]bool
+}
+
+type symlinkDirEntry struct{ fs.DirEntry }
+
+func (d symlinkDirEntry) Type() fs.FileMode { return d.DirEntry.Type() | fs.ModeSymlink }
+
+type symlinkInfo struct{ fs.FileInfo }
+
+func (fi symlinkInfo) Mode() fs.FileMode { return fi.FileInfo.Mode() | fs.ModeSymlink }
+
+func (fsys followFS) Lstat(name string) (fs.FileInfo, error) {
+	info, err := fsys.MapFS.Stat(name)
+	if err == nil && fsys.links[name] {
+		info = symlinkInfo{info}
+	}
+	return info, err
+}
+
+func (fsys followFS) ReadDir(name string) ([]fs.DirEntry, error) {
+	entries, err := fsys.MapFS.ReadDir(name)
+	for i, d := range entries {
+		if fsys.links[path.Join(name, d.Name())] {
+			entries[i] = symlinkDirEntry{d}
+		}
+	}
+	return entries, err
+}
+
+func TestRanger_NoFollowDirs(t *testing.T) {
+	fsys := followFS{
+		MapFS: fstest.MapFS{
+			"real/file.txt":     &fstest.MapFile{},
+			"sub/link/file.txt": &fstest.MapFile{},
+		},
+		links: map[string]bool{"sub/link": true},
+	}
+	tr := walker.New(fsys, ".", walker.OnErrorHalt)
+	paths := slices.Collect(tr.Paths())
+	be.NilErr(t, tr.Err())
+	be.Equal(t, ".; real; real/file.txt; sub; sub/link; sub/link/file.txt", strings.Join(paths, "; "))
+
+	tr.NoFollowDirs(true)
+	paths = slices.Collect(tr.Paths())
+	be.NilErr(t, tr.Err())
+	be.Equal(t, ".; real; real/file.txt; sub; sub/link", strings.Join(paths, "; "))
+}
+
+func TestRanger_Include_midWalk(t *testing.T) {
+	testFS := fstest.MapFS{
+		"a.txt":                &fstest.MapFile{},
+		"config.toml":          &fstest.MapFile{},
+		"dir1/file3.txt":       &fstest.MapFile{},
+		"dir1/file4.log":       &fstest.MapFile{},
+		"dir2/file5.txt":       &fstest.MapFile{},
+		"dir2/subdir/file6.go": &fstest.MapFile{},
+	}
+	tr := walker.New(testFS, ".", walker.OnErrorHalt)
+	var paths []string
+	for path := range tr.FilePaths() {
+		paths = append(paths, path)
+		if path == "config.toml" {
+			tr.Include(walker.MatchExtension(".log", ".go"))
+		}
+	}
+	be.NilErr(t, tr.Err())
+	be.Equal(t, "a.txt; config.toml; dir1/file4.log; dir2/subdir/file6.go", strings.Join(paths, "; "))
+}
+
+func TestRanger_Batches(t *testing.T) {
+	testFS := fstest.MapFS{
+		"a.txt":                &fstest.MapFile{},
+		"dir1/file3.txt":       &fstest.MapFile{},
+		"dir1/file4.log":       &fstest.MapFile{},
+		"dir2/file5.txt":       &fstest.MapFile{},
+		"dir2/subdir/file6.go": &fstest.MapFile{},
+	}
+	tr := walker.New(testFS, ".", walker.OnErrorHalt)
+	for _, tc := range []struct {
+		size  int
+		sizes string
+	}{
+		{3, "3 3 3"},
+		{4, "4 4 1"},
+		{20, "9"},
+		{0, "1 1 1 1 1 1 1 1 1"},
+	} {
+		var sizes []string
+		var paths []string
+		for batch := range tr.Batches(tc.size) {
+			sizes = append(sizes, fmt.Sprint(len(batch)))
+			for _, e := range batch {
+				paths = append(paths, e.Path)
+			}
+		}
+		be.NilErr(t, tr.Err())
+		be.Equal(t, tc.sizes, strings.Join(sizes, " "))
+		be.Equal(t, strings.Join(slices.Collect(tr.Paths()), "; "), strings.Join(paths, "; "))
+	}
+
+	var batches [][]walker.Entry
+	for batch := range tr.Batches(4) {
+		batches = append(batches, batch)
+		if len(batches) == 2 {
+			break
+		}
+	}
+	be.Equal(t, ".", batches[0][0].Path)
+	be.Equal(t, "dir1/file4.log", batches[1][0].Path)
+}
+
+func TestRanger_OnSkipDir(t *testing.T) {
+	testFS := fstest.MapFS{
+		"a.txt":                &fstest.MapFile{},
+		"dir1/file3.txt":       &fstest.MapFile{},
+		"dir2/subdir/file6.go": &fstest.MapFile{},
+		"node_modules/x.js":    &fstest.MapFile{},
+	}
+	tr := walker.New(testFS, ".", walker.OnErrorHalt)
+	var skipped []string
+	tr.OnSkipDir(func(e walker.Entry, reason string) {
+		skipped = append(skipped, e.Path+" ("+reason+")")
+	})
+	tr.ExcludeDir(walker.MatchGlobName("node_modules"))
+	tr.IncludeDir(walker.Not(walker.MatchGlobName("subdir")))
+	paths := slices.Collect(tr.FilePaths())
+	be.NilErr(t, tr.Err())
+	be.Equal(t, "a.txt; dir1/file3.txt", strings.Join(paths, "; "))
+	be.Equal(t, "dir2/subdir (include-dirs); node_modules (exclude-dirs)", strings.Join(skipped, "; "))
+
+	skipped = nil
+	tr.OnSkipDir(nil)
+	_ = slices.Collect(tr.FilePaths())
+	be.Equal(t, 0, len(skipped))
+}
+
+func TestRanger_OnEnterDir(t *testing.T) {
+	testFS := fstest.MapFS{
+		"a.txt":                &fstest.MapFile{},
+		"dir1/file3.txt":       &fstest.MapFile{},
+		"dir2/file5.txt":       &fstest.MapFile{},
+		"dir2/subdir/file6.go": &fstest.MapFile{},
+		"dir3/file7.txt":       &fstest.MapFile{},
+		"file1.txt":            &fstest.MapFile{},
+	}
+	tr := walker.New(testFS, ".", walker.OnErrorHalt)
+	var events []string
+	tr.OnEnterDir(func(e walker.Entry) { events = append(events, "enter "+e.Path) })
+	tr.OnLeaveDir(func(e walker.Entry) { events = append(events, "leave "+e.Path) })
+	tr.ExcludeDir(walker.MatchGlobName("dir3"))
+	for path := range tr.FilePaths() {
+		events = append(events, path)
+	}
+	be.NilErr(t, tr.Err())
+	be.Equal(t, strings.Join([]string{
+		"enter .",
+		"a.txt",
+		"enter dir1",
+		"dir1/file3.txt",
+		"leave dir1",
+		"enter dir2",
+		"dir2/file5.txt",
+		"enter dir2/subdir",
+		"dir2/subdir/file6.go",
+		"leave dir2/subdir",
+		"leave dir2",
+		"file1.txt",
+		"leave .",
+	}, "; "), strings.Join(events, "; "))
+
+	events = nil
+	for path := range tr.FilePaths() {
+		events = append(events, path)
+		if path == "dir2/subdir/file6.go" {
+			break
+		}
+	}
+	be.Equal(t, "leave dir2/subdir; leave dir2; leave .",
+		strings.Join(events[len(events)-3:], "; "))
+}
+
+func TestList(t *testing.T) {
+	testFS := fstest.MapFS{
+		"a.txt":          &fstest.MapFile{},
+		"dir1/file3.txt": &fstest.MapFile{},
+		"dir1/file4.log": &fstest.MapFile{},
+	}
+	temp := t.TempDir()
+	be.NilErr(t, os.CopyFS(temp, testFS))
+	rel := func(paths []string) string {
+		for i := range paths {
+			paths[i], _ = filepath.Rel(temp, paths[i])
+			paths[i] = filepath.ToSlash(paths[i])
+		}
+		return strings.Join(paths, "; ")
+	}
+
+	paths, err := walker.List(temp, walker.MatchExtension(".txt"))
+	be.NilErr(t, err)
+	be.Equal(t, "a.txt; dir1/file3.txt", rel(paths))
+
+	paths, err = walker.List(temp, nil)
+	be.NilErr(t, err)
+	be.Equal(t, "a.txt; dir1/file3.txt; dir1/file4.log", rel(paths))
+
+	dir := tempDirWithPermErr(t)
+	paths, err = walker.List(dir, nil)
+	be.True(t, errors.Is(err, fs.ErrPermission))
+	be.Equal(t, 1, len(paths))
+}
+
+func TestFind(t *testing.T) {
+	testFS := fstest.MapFS{
+		"go.mod":             &fstest.MapFile{},
+		"dir1/go.mod":        &fstest.MapFile{},
+		"dir1/go.sum":        &fstest.MapFile{},
+		"dir2/sub/go.mod":    &fstest.MapFile{},
+		"dir2/sub/go.mod.go": &fstest.MapFile{},
+		"go.mod.d/x.txt":     &fstest.MapFile{},
+	}
+	temp := t.TempDir()
+	be.NilErr(t, os.CopyFS(temp, testFS))
+	rel := func(paths []string) string {
+		for i := range paths {
+			paths[i], _ = filepath.Rel(temp, paths[i])
+			paths[i] = filepath.ToSlash(paths[i])
+		}
+		return strings.Join(paths, "; ")
+	}
+
+	paths, err := walker.Find(temp, "go.mod")
+	be.NilErr(t, err)
+	be.Equal(t, "dir1/go.mod; dir2/sub/go.mod; go.mod", rel(paths))
+
+	paths, err = walker.Find(temp, "missing.txt")
+	be.NilErr(t, err)
+	be.Equal(t, 0, len(paths))
+
+	_, err = walker.Find(filepath.Join(temp, "nope"), "go.mod")
+	be.True(t, errors.Is(err, fs.ErrNotExist))
+
+	dir := tempDirWithPermErr(t)
+	paths, err = walker.Find(dir, "3.txt")
+	be.NilErr(t, err)
+	be.Equal(t, 1, len(paths))
+}
+
+func TestRanger_LimitPerDir(t *testing.T) {
+	testFS := fstest.MapFS{
+		"a.txt":    &fstest.MapFile{},
+		"b.log":    &fstest.MapFile{},
+		"sub/x.go": &fstest.MapFile{},
+	}
+	for i := range 10 {
+		testFS[fmt.Sprintf("many/file%d.txt", i)] = &fstest.MapFile{}
+	}
+	tr := walker.New(testFS, ".", walker.OnErrorHalt)
+	tr.LimitPerDir(3)
+	tr.Exclude(walker.MatchExtension(".log"))
+	for range 2 {
+		paths := slices.Collect(tr.FilePaths())
+		be.NilErr(t, tr.Err())
+		be.Equal(t, "a.txt; many/file0.txt; many/file1.txt; many/file2.txt; sub/x.go",
+			strings.Join(paths, "; "))
+	}
+
+	tr.LimitPerDir(0)
+	be.Equal(t, 12, len(slices.Collect(tr.FilePaths())))
+}
+
+func TestRanger_RequireRoot(t *testing.T) {
+	missing := filepath.Join(t.TempDir(), "does-not-exist")
+	var errs []error
+	for _, erp := range []walker.ErrorPolicy{
+		walker.OnErrorIgnore,
+		walker.OnErrorCollect(&errs),
+		walker.OnErrorPanic,
+	} {
+		tr := walker.New(nil, missing, erp)
+		tr.RequireRoot(true)
+		paths := slices.Collect(tr.FilePaths())
+		be.Equal(t, 0, len(paths))
+		be.True(t, errors.Is(tr.Err(), fs.ErrNotExist))
+		be.In(t, "invalid root", tr.Err().Error())
+	}
+	be.Equal(t, 0, len(errs))
+
+	tr := walker.New(fstest.MapFS{"a.txt": &fstest.MapFile{}}, ".", walker.OnErrorIgnore)
+	tr.RequireRoot(true)
+	be.Equal(t, "a.txt", strings.Join(slices.Collect(tr.FilePaths()), "; "))
+	be.NilErr(t, tr.Err())
+}
+
+func TestRanger_RelEntries(t *testing.T) {
+	testFS := fstest.MapFS{
+		"a.txt":                &fstest.MapFile{},
+		"dir1/file3.txt":       &fstest.MapFile{},
+		"dir2/subdir/file6.go": &fstest.MapFile{},
+	}
+	temp := t.TempDir()
+	be.NilErr(t, os.CopyFS(temp, testFS))
+
+	for _, tc := range []struct {
+		tr   walker.Ranger
+		want string
+	}{
+		{walker.New(testFS, ".", walker.OnErrorHalt),
+			". a.txt dir1 dir1/file3.txt dir2 dir2/subdir dir2/subdir/file6.go"},
+		{walker.New(testFS, "dir2", walker.OnErrorHalt),
+			". subdir subdir/file6.go"},
+		{walker.New(nil, temp, walker.OnErrorHalt),
+			". a.txt dir1 dir1/file3.txt dir2 dir2/subdir dir2/subdir/file6.go"},
+	} {
+		var rels []string
+		for rel, e := range tc.tr.RelEntries() {
+			be.Equal(t, e.RelRoot(), rel)
+			rels = append(rels, filepath.ToSlash(rel))
+		}
+		be.NilErr(t, tc.tr.Err())
+		be.Equal(t, tc.want, strings.Join(rels, " "))
+	}
+}
+
+func TestRanger_RelPaths(t *testing.T) {
+	testFS := fstest.MapFS{
+		"a.txt":                &fstest.MapFile{},
+		"dir1/file3.txt":       &fstest.MapFile{},
+		"dir2/subdir/file6.go": &fstest.MapFile{},
+	}
+	temp := t.TempDir()
+	be.NilErr(t, os.CopyFS(temp, testFS))
+
+	tr := walker.New(testFS, ".", walker.OnErrorHalt)
+	fsPaths := slices.Collect(tr.RelPaths())
+	be.Equal(t, ". a.txt dir1 dir1/file3.txt dir2 dir2/subdir dir2/subdir/file6.go", strings.Join(fsPaths, " "))
+
+	tr = walker.New(nil, temp, walker.OnErrorHalt)
+	osPaths := slices.Collect(tr.RelPaths())
+	be.NilErr(t, tr.Err())
+	for i := range osPaths {
+		osPaths[i] = filepath.ToSlash(osPaths[i])
+	}
+	be.AllEqual(t, fsPaths, osPaths)
+
+	tr = walker.New(testFS, "dir2", walker.OnErrorHalt)
+	tr.Include(walker.MatchExtension(".go"))
+	be.Equal(t, "subdir/file6.go", strings.Join(slices.Collect(tr.RelPaths()), " "))
+}
+
+func TestRanger_CoalesceErrors(t *testing.T) {
+	dir := t.TempDir()
+	for i := range 20 {
+		sub := filepath.Join(dir, fmt.Sprintf("sub%02d", i))
+		be.NilErr(t, os.Mkdir(sub, 0o755))
+		be.NilErr(t, os.WriteFile(filepath.Join(sub, "a.txt"), nil, 0o644))
+		be.NilErr(t, os.Chmod(sub, 0o000))
+		t.Cleanup(func() {
+			be.NilErr(t, os.Chmod(sub, 0o777))
+		})
+	}
+
+	var errs []error
+	tr := walker.New(nil, dir, walker.OnErrorCollect(&errs))
+	_ = slices.Collect(tr.FilePaths())
+	be.Equal(t, 20, len(errs))
+
+	errs = nil
+	tr.CoalesceErrors()
+	for range 2 {
+		_ = slices.Collect(tr.FilePaths())
+	}
+	be.Equal(t, 2, len(errs))
+	be.True(t, errors.Is(errs[0], fs.ErrPermission))
+	be.In(t, "sub00", errs[0].Error())
+}
+
+func TestErrorCollector(t *testing.T) {
+	dir := tempDirWithPermErr(t)
+
+	var c walker.ErrorCollector
+	tr := walker.New(nil, dir, c.Policy)
+	var wg sync.WaitGroup
+	for range 8 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			clone := tr.Clone()
+			for range clone.FilePaths() {
+			}
+			c.Append(errors.New("done"))
+		}()
+	}
+	wg.Wait()
+	errs := c.Errors()
+	be.Equal(t, 16, len(errs))
+	var perm int
+	for _, err := range errs {
+		if errors.Is(err, fs.ErrPermission) {
+			perm++
+		}
+	}
+	be.Equal(t, 8, perm)
+}
+
+func TestNew_cleanRoot(t *testing.T) {
+	temp := t.TempDir()
+	be.NilErr(t, os.CopyFS(temp, fstest.MapFS{
+		"bar/a.txt": &fstest.MapFile{},
+		"foo/b.txt": &fstest.MapFile{},
+	}))
+	root := filepath.Join(temp, "foo") + string(filepath.Separator) + ".." +
+		string(filepath.Separator) + "bar"
+	tr := walker.New(nil, root, walker.OnErrorHalt)
+	var paths []string
+	for e := range tr.Entries() {
+		paths = append(paths, filepath.ToSlash(e.RelRoot()))
+		be.False(t, strings.Contains(e.Path, ".."))
+	}
+	be.NilErr(t, tr.Err())
+	be.Equal(t, ". a.txt", strings.Join(paths, " "))
+
+	testFS := fstest.MapFS{"bar/a.txt": &fstest.MapFile{}}
+	for _, root := range []string{"foo/../bar", "../bar", "/bar", "bar/"} {
+		tr := walker.New(testFS, root, walker.OnErrorHalt)
+		be.True(t, errors.Is(tr.Validate(), fs.ErrInvalid))
+		paths := slices.Collect(tr.Paths())
+		be.Equal(t, 0, len(paths))
+		be.True(t, errors.Is(tr.Err(), fs.ErrInvalid))
+		be.In(t, "invalid fs.FS root", tr.Err().Error())
+	}
+}
+
+func TestRanger_TrySkipDir(t *testing.T) {
+	testFS := fstest.MapFS{
+		"a.txt":          &fstest.MapFile{},
+		"dir1/file3.txt": &fstest.MapFile{},
+		"dir2/file5.txt": &fstest.MapFile{},
+	}
+	tr := walker.New(testFS, ".", walker.OnErrorHalt)
+	be.False(t, tr.TrySkipDir())
+	be.Nonzero(t, try(tr.SkipDir))
+
+	var paths []string
+	for e := range tr.Entries() {
+		paths = append(paths, e.Path)
+		if e.Path == "dir1" {
+			be.True(t, tr.TrySkipDir())
+		}
+	}
+	be.NilErr(t, tr.Err())
+	be.Equal(t, ".; a.txt; dir1; dir2; dir2/file5.txt", strings.Join(paths, "; "))
+	be.False(t, tr.TrySkipDir())
+}
@@ -0,0 +1,67 @@
+package walker
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"slices"
+)
+
+// Remove walks the tree and deletes the matching files and directories
+// from the OS filesystem, returning the paths it removed.
+// Directories are removed after their contents,
+// and a matching directory that still holds unmatched entries is left in place.
+// The root of the walk is never removed.
+// Symbolic links are removed themselves and never followed,
+// even if FollowSymlinks is set,
+// so nothing outside the tree is removed.
+// Failures to remove an entry are passed to the ErrorPolicy,
+// and if it halts, Remove stops and returns the failure.
+// Otherwise, it returns the Ranger's Err().
+// Remove returns an error wrapping errors.ErrUnsupported
+// for a Ranger that walks an fs.FS or uses NewFunc, which are read-only.
+func (tr *Ranger) Remove() (removed []string, err error) {
+	if !tr.useFilepath() {
+		return nil, fmt.Errorf("walker: Remove requires the OS filesystem: %w", errors.ErrUnsupported)
+	}
+	followLinks := tr.followLinks
+	tr.followLinks = false
+	defer func() { tr.followLinks = followLinks }()
+	var dirs []Entry
+	for e := range tr.Entries() {
+		if e.IsDir() {
+			// With AbsolutePaths, e.Path differs from tr.root.
+			if e.RelRoot() != "." {
+				dirs = append(dirs, e)
+			}
+			continue
+		}
+		if err := os.Remove(e.Path); err != nil {
+			if !tr.handleError(err, e) {
+				tr.lastErr, tr.errEntry = err, e
+				return removed, err
+			}
+			continue
+		}
+		removed = append(removed, e.Path)
+	}
+	if err := tr.Err(); err != nil {
+		return removed, err
+	}
+	// The walk yields parents before their children,
+	// so go backwards to remove children first.
+	for _, e := range slices.Backward(dirs) {
+		if err := os.Remove(e.Path); err != nil {
+			if names, _ := os.ReadDir(e.Path); len(names) > 0 {
+				continue
+			}
+			if !tr.handleError(err, e) {
+				tr.lastErr, tr.errEntry = err, e
+				return removed, err
+			}
+			continue
+		}
+		removed = append(removed, e.Path)
+	}
+	return removed, nil
+}
@@ -0,0 +1,144 @@
+package walker_test
+
+import (
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+	"testing/fstest"
+
+	"github.com/carlmjohnson/be"
+	"github.com/earthboundkid/walker"
+)
+
+func TestRanger_Remove(t *testing.T) {
+	testFS := fstest.MapFS{
+		"a.txt":          &fstest.MapFile{},
+		"debug.log":      &fstest.MapFile{},
+		"dir1/file3.txt": &fstest.MapFile{},
+		"dir1/file4.log": &fstest.MapFile{},
+		"logs/x.log":     &fstest.MapFile{},
+		"logs/y.log":     &fstest.MapFile{},
+	}
+	temp := t.TempDir()
+	be.NilErr(t, os.CopyFS(temp, testFS))
+	rel := func(paths []string) string {
+		for i := range paths {
+			paths[i], _ = filepath.Rel(temp, paths[i])
+			paths[i] = filepath.ToSlash(paths[i])
+		}
+		return strings.Join(paths, "; ")
+	}
+
+	tr := walker.New(nil, temp, walker.OnErrorHalt)
+	tr.Include(walker.MatchExtension(".log"))
+	removed, err := tr.Remove()
+	be.NilErr(t, err)
+	be.Equal(t, "debug.log; dir1/file4.log; logs/x.log; logs/y.log", rel(removed))
+
+	remaining, err := walker.List(temp, nil)
+	be.NilErr(t, err)
+	be.Equal(t, "a.txt; dir1/file3.txt", rel(remaining))
+
+	// Matching directories are removed after their contents,
+	// unless unmatched entries remain.
+	tr = walker.New(nil, temp, walker.OnErrorHalt)
+	tr.Exclude(walker.MatchExtension(".txt"))
+	removed, err = tr.Remove()
+	be.NilErr(t, err)
+	be.Equal(t, "logs", rel(removed))
+	tr = walker.New(nil, temp, walker.OnErrorHalt)
+	remaining = slices.Collect(tr.Paths())
+	be.Equal(t, ".; a.txt; dir1; dir1/file3.txt", rel(remaining))
+
+	tr = walker.New(os.DirFS(temp), ".", walker.OnErrorHalt)
+	removed, err = tr.Remove()
+	be.True(t, errors.Is(err, errors.ErrUnsupported))
+	be.Equal(t, 0, len(removed))
+	_, err = fs.Stat(os.DirFS(temp), "a.txt")
+	be.NilErr(t, err)
+}
+
+func TestRanger_Remove_halt(t *testing.T) {
+	if os.Geteuid() == 0 {
+		t.Skip("root can remove any file")
+	}
+	temp := t.TempDir()
+	locked := filepath.Join(temp, "locked")
+	be.NilErr(t, os.Mkdir(locked, 0o755))
+	be.NilErr(t, os.WriteFile(filepath.Join(locked, "x.log"), nil, 0o644))
+	be.NilErr(t, os.Chmod(locked, 0o555))
+	t.Cleanup(func() { _ = os.Chmod(locked, 0o755) })
+
+	tr := walker.New(nil, temp, walker.OnErrorHalt)
+	tr.Include(walker.MatchExtension(".log"))
+	removed, err := tr.Remove()
+	be.True(t, errors.Is(err, fs.ErrPermission))
+	be.Equal(t, 0, len(removed))
+	be.Equal(t, err, tr.Err())
+	be.Equal(t, filepath.Join(locked, "x.log"), tr.ErrPath())
+}
+
+func TestRanger_Remove_continue(t *testing.T) {
+	if os.Geteuid() == 0 {
+		t.Skip("root can remove any file")
+	}
+	temp := t.TempDir()
+	be.NilErr(t, os.MkdirAll(filepath.Join(temp, "a"), 0o755))
+	be.NilErr(t, os.WriteFile(filepath.Join(temp, "a/x.log"), nil, 0o644))
+	locked := filepath.Join(temp, "zlocked")
+	be.NilErr(t, os.MkdirAll(filepath.Join(locked, "empty"), 0o755))
+	be.NilErr(t, os.WriteFile(filepath.Join(locked, "y.log"), nil, 0o644))
+	be.NilErr(t, os.Chmod(locked, 0o555))
+	t.Cleanup(func() { _ = os.Chmod(locked, 0o755) })
+
+	// The last entry walked, zlocked/y.log, cannot be removed,
+	// nor can the empty directory zlocked/empty,
+	// but the policy continues, so a is still removed.
+	var errs []error
+	tr := walker.New(nil, temp, walker.OnErrorCollect(&errs))
+	removed, err := tr.Remove()
+	be.NilErr(t, err)
+	be.NilErr(t, tr.Err())
+	be.Equal(t, 2, len(errs))
+	var got []string
+	for _, p := range removed {
+		rel, _ := filepath.Rel(temp, p)
+		got = append(got, filepath.ToSlash(rel))
+	}
+	be.Equal(t, "a/x.log; a", strings.Join(got, "; "))
+}
+
+func TestRanger_Remove_root(t *testing.T) {
+	temp := t.TempDir()
+	wd, err := os.Getwd()
+	be.NilErr(t, err)
+	be.NilErr(t, os.Chdir(temp))
+	t.Cleanup(func() { be.NilErr(t, os.Chdir(wd)) })
+	be.NilErr(t, os.MkdirAll("root/dir1", 0o755))
+	be.NilErr(t, os.MkdirAll("outside", 0o755))
+	be.NilErr(t, os.WriteFile("root/dir1/a.txt", nil, 0o644))
+	be.NilErr(t, os.WriteFile("outside/keep.txt", nil, 0o644))
+	be.NilErr(t, os.Symlink("../outside", "root/link"))
+
+	tr := walker.New(nil, "root", walker.OnErrorHalt)
+	tr.AbsolutePaths(true)
+	tr.FollowSymlinks(true)
+	removed, err := tr.Remove()
+	be.NilErr(t, err)
+	abs, err := filepath.Abs("root")
+	be.NilErr(t, err)
+	be.Equal(t, 3, len(removed))
+	be.False(t, slices.Contains(removed, abs))
+	be.True(t, slices.Contains(removed, filepath.Join(abs, "link")))
+
+	// The root is kept, and the link is removed without following it.
+	entries, err := os.ReadDir("root")
+	be.NilErr(t, err)
+	be.Equal(t, 0, len(entries))
+	_, err = os.Stat("outside/keep.txt")
+	be.NilErr(t, err)
+}
@@ -0,0 +1,117 @@
+package walker
+
+import (
+	"io/fs"
+	"path"
+	"path/filepath"
+	"slices"
+	"strings"
+)
+
+// NewResume creates a new Ranger like New
+// that skips every entry up to and including cursor,
+// which should be a value previously returned by Ranger.Cursor.
+// Because entries are walked in lexical order, the resumed walk
+// yields exactly the entries that the original walk had not yet reached.
+// The filters of the resumed Ranger must be identical to the original's
+// or entries may be skipped or repeated.
+func NewResume(fsys fs.FS, root string, erp ErrorPolicy, cursor string) Ranger {
+	tr := New(fsys, root, erp)
+	tr.resumeAfter = cursor
+	return tr
+}
+
+// Cursor returns an opaque token recording the last entry yielded by the Ranger.
+// Pass it to NewResume to continue a walk where it left off.
+func (tr *Ranger) Cursor() string {
+	return tr.lastYielded
+}
+
+// skipResumed reports whether e comes at or before the resume cursor.
+// Directories before the cursor that do not contain it are skipped.
+func (tr *Ranger) skipResumed(e Entry) bool {
+	if tr.resumeAfter == "" {
+		return false
+	}
+	cursor := Entry{
+		Path:        tr.resumeAfter,
+		root:        e.root,
+		useFilepath: e.useFilepath,
+	}
+	segs, cursorSegs := e.relSegments(), cursor.relSegments()
+	cmp := slices.Compare(segs, cursorSegs)
+	if cmp > 0 {
+		return false
+	}
+	if e.IsDir() && !isPrefix(segs, cursorSegs) {
+		tr.SkipDir()
+	}
+	return true
+}
+
+// relSegments splits RelRoot into its components.
+// Comparing the segments of two entries gives their order in a lexical walk.
+func (e Entry) relSegments() []string {
+	rel := e.RelRoot()
+	if rel == "." {
+		return nil
+	}
+	if e.useFilepath {
+		rel = filepath.ToSlash(rel)
+	}
+	return strings.Split(rel, "/")
+}
+
+// isPrefix reports whether prefix is a leading subslice of s.
+func isPrefix(prefix, s []string) bool {
+	return len(prefix) <= len(s) && slices.Equal(prefix, s[:len(prefix)])
+}
+
+// Range tells the Ranger to walk only the entries
+// whose slash separated path relative to the root is at least from
+// and less than to, so that several workers can split a tree between them.
+// Paths are compared element by element, which is the order of a lexical walk,
+// so "a/b" comes before "a-b".
+// An empty from starts at the beginning, including the root,
+// and an empty to continues to the end.
+// Directories before from that do not contain it are skipped,
+// and the walk halts at the first entry at or after to,
+// so the backend must list directories in lexical order,
+// as the OS filesystem and fs.ReadDir do; see StableOrder.
+func (tr *Ranger) Range(from, to string) {
+	tr.rangeFrom, tr.rangeTo = splitRange(from), splitRange(to)
+}
+
+// splitRange splits a slash separated path into its components.
+// An empty path returns nil.
+func splitRange(p string) []string {
+	if p == "" {
+		return nil
+	}
+	p = path.Clean(filepath.ToSlash(p))
+	if p == "." {
+		return []string{}
+	}
+	return strings.Split(p, "/")
+}
+
+// beforeRange reports whether e comes before the start of the Range.
+// Directories before the start that do not contain it are skipped.
+func (tr *Ranger) beforeRange(e Entry) bool {
+	if tr.rangeFrom == nil {
+		return false
+	}
+	segs := e.relSegments()
+	if slices.Compare(segs, tr.rangeFrom) >= 0 {
+		return false
+	}
+	if e.IsDir() && !isPrefix(segs, tr.rangeFrom) {
+		tr.SkipDir()
+	}
+	return true
+}
+
+// pastRange reports whether e is at or after the end of the Range.
+func (tr *Ranger) pastRange(e Entry) bool {
+	return tr.rangeTo != nil && slices.Compare(e.relSegments(), tr.rangeTo) >= 0
+}
@@ -0,0 +1,40 @@
+package walker
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// ExpandRoot expands a leading "~" in root to the current user's home directory
+// and replaces $VAR and ${VAR} with the values of environment variables,
+// as a shell would for a root given on the command line.
+// A tilde elsewhere in root, or followed by a user name, is left as is.
+// It returns an error if the home directory cannot be determined
+// or if root refers to an unset environment variable.
+// ExpandRoot is meant for OS roots; fs.FS roots should not be expanded.
+func ExpandRoot(root string) (string, error) {
+	var home string
+	if root == "~" || strings.HasPrefix(root, "~/") ||
+		strings.HasPrefix(root, "~"+string(filepath.Separator)) {
+		var err error
+		home, err = os.UserHomeDir()
+		if err != nil {
+			return "", fmt.Errorf("walker: expanding root %q: %w", root, err)
+		}
+		root = root[1:]
+	}
+	var missing []string
+	root = os.Expand(root, func(name string) string {
+		v, ok := os.LookupEnv(name)
+		if !ok {
+			missing = append(missing, name)
+		}
+		return v
+	})
+	if len(missing) > 0 {
+		return "", fmt.Errorf("walker: expanding root: unset environment variable %q", missing[0])
+	}
+	return home + root, nil
+}
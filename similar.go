@@ -0,0 +1,169 @@
+package walker
+
+import (
+	"bufio"
+	"cmp"
+	"hash/fnv"
+	"io"
+	"math"
+	"slices"
+)
+
+// Constants for the MinHash signatures used by SimilarGroups.
+const (
+	minHashSize  = 128
+	shingleWidth = 4
+)
+
+// SimilarGroups walks the tree and groups the matching files,
+// ignoring directories, whose contents are similar,
+// such as slightly edited copies of a document.
+//
+// Each file is summarized by a MinHash signature:
+// its contents are split into overlapping 4 byte shingles,
+// and for each of 128 hash functions the signature records
+// the smallest hash of any shingle.
+// The fraction of matching signature values between two files
+// estimates the Jaccard similarity of their shingle sets,
+// from 0 for unrelated files to 1 for files with the same shingles.
+// Signatures are computed in a single streaming pass over each file,
+// so files are never loaded into memory in full,
+// but every pair of files is compared,
+// which is slow for trees with very many files.
+//
+// Files are grouped when their estimated similarity is at least threshold,
+// and groups are joined transitively,
+// so two files in a group may be connected through a third
+// without being similar to each other.
+// Only groups of two or more files are returned.
+// Paths within a group are sorted,
+// and groups are sorted by their first path.
+// Failures to read a file are passed to the ErrorPolicy,
+// and if it halts, SimilarGroups stops and returns the failure.
+// Otherwise, it returns the Ranger's Err().
+func (tr *Ranger) SimilarGroups(threshold float64) ([][]string, error) {
+	var paths []string
+	var sigs [][minHashSize]uint64
+	for e := range tr.FileEntries() {
+		sig, err := minHashFile(e)
+		if err != nil {
+			if !tr.handleError(err, e) {
+				tr.lastErr, tr.errEntry = err, e
+				return nil, err
+			}
+			continue
+		}
+		paths = append(paths, e.Path)
+		sigs = append(sigs, sig)
+	}
+	if err := tr.Err(); err != nil {
+		return nil, err
+	}
+
+	// Union-find over the indexes of similar pairs.
+	parent := make([]int, len(paths))
+	for i := range parent {
+		parent[i] = i
+	}
+	var find func(i int) int
+	find = func(i int) int {
+		if parent[i] != i {
+			parent[i] = find(parent[i])
+		}
+		return parent[i]
+	}
+	for i := range sigs {
+		for j := i + 1; j < len(sigs); j++ {
+			if similarity(&sigs[i], &sigs[j]) >= threshold {
+				parent[find(j)] = find(i)
+			}
+		}
+	}
+
+	byRoot := make(map[int][]string)
+	for i, p := range paths {
+		root := find(i)
+		byRoot[root] = append(byRoot[root], p)
+	}
+	var groups [][]string
+	for _, group := range byRoot {
+		if len(group) > 1 {
+			slices.Sort(group)
+			groups = append(groups, group)
+		}
+	}
+	slices.SortFunc(groups, func(a, b []string) int {
+		return cmp.Compare(a[0], b[0])
+	})
+	return groups, nil
+}
+
+// minHashFile returns the MinHash signature of the contents of e.
+// Files shorter than a shingle are treated as a single shingle.
+func minHashFile(e Entry) ([minHashSize]uint64, error) {
+	var sig [minHashSize]uint64
+	for i := range sig {
+		sig[i] = math.MaxUint64
+	}
+	f, err := e.Open()
+	if err != nil {
+		return sig, err
+	}
+	defer f.Close()
+	r := bufio.NewReader(f)
+	var window [shingleWidth]byte
+	n := 0
+	h := fnv.New64a()
+	add := func(shingle []byte) {
+		h.Reset()
+		h.Write(shingle)
+		base := h.Sum64()
+		for i := range sig {
+			sig[i] = min(sig[i], mix64(base^uint64(i)*0x9e3779b97f4a7c15))
+		}
+	}
+	for {
+		c, err := r.ReadByte()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return sig, err
+		}
+		if n == shingleWidth {
+			copy(window[:], window[1:])
+			n--
+		}
+		window[n] = c
+		n++
+		if n == shingleWidth {
+			add(window[:])
+		}
+	}
+	if n < shingleWidth {
+		add(window[:n])
+	}
+	return sig, nil
+}
+
+// mix64 is the SplitMix64 finalizer,
+// which derives independent hash values from a single base hash.
+func mix64(x uint64) uint64 {
+	x ^= x >> 30
+	x *= 0xbf58476d1ce4e5b9
+	x ^= x >> 27
+	x *= 0x94d049bb133111eb
+	x ^= x >> 31
+	return x
+}
+
+// similarity returns the fraction of matching values in two signatures.
+func similarity(a, b *[minHashSize]uint64) float64 {
+	n := 0
+	for i := range a {
+		if a[i] == b[i] {
+			n++
+		}
+	}
+	return float64(n) / minHashSize
+}
@@ -0,0 +1,54 @@
+package walker_test
+
+import (
+	"errors"
+	"io/fs"
+	"strings"
+	"testing"
+	"testing/fstest"
+
+	"github.com/carlmjohnson/be"
+	"github.com/earthboundkid/walker"
+)
+
+func TestRanger_SimilarGroups(t *testing.T) {
+	doc := strings.Repeat("The quick brown fox jumps over the lazy dog. ", 40)
+	testFS := fstest.MapFS{
+		"doc.txt":       {Data: []byte(doc)},
+		"copy/doc.txt":  {Data: []byte(strings.Replace(doc, "lazy", "sleepy", 1))},
+		"other.txt":     {Data: []byte(strings.Repeat("Lorem ipsum dolor sit amet, consectetur. ", 40))},
+		"tiny/a.txt":    {Data: []byte("ab")},
+		"tiny/b.txt":    {Data: []byte("ab")},
+		"tiny/dir/x.md": {Data: []byte("xyz")},
+	}
+	tr := walker.New(testFS, ".", walker.OnErrorHalt)
+	groups, err := tr.SimilarGroups(0.8)
+	be.NilErr(t, err)
+	var got []string
+	for _, g := range groups {
+		got = append(got, strings.Join(g, ", "))
+	}
+	be.Equal(t, "copy/doc.txt, doc.txt; tiny/a.txt, tiny/b.txt", strings.Join(got, "; "))
+
+	tr.ExcludeDir(walker.MatchGlobName("tiny"))
+	groups, err = tr.SimilarGroups(1)
+	be.NilErr(t, err)
+	be.Equal(t, 0, len(groups))
+
+	groups, err = tr.SimilarGroups(0)
+	be.NilErr(t, err)
+	be.Equal(t, 1, len(groups))
+	be.Equal(t, "copy/doc.txt, doc.txt, other.txt", strings.Join(groups[0], ", "))
+
+	tr = walker.New(badOpenFS{testFS, "other.txt"}, ".", walker.OnErrorHalt)
+	_, err = tr.SimilarGroups(0.8)
+	be.True(t, errors.Is(err, fs.ErrPermission))
+	be.Equal(t, err, tr.Err())
+	be.Equal(t, "other.txt", tr.ErrPath())
+
+	tr = walker.New(badOpenFS{testFS, "tiny/dir/x.md"}, ".", walker.OnErrorIgnore)
+	groups, err = tr.SimilarGroups(0.8)
+	be.NilErr(t, err)
+	be.NilErr(t, tr.Err())
+	be.Equal(t, 2, len(groups))
+}
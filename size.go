@@ -0,0 +1,131 @@
+package walker
+
+import (
+	"fmt"
+	"math"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// MatchSize returns a FilterFunc that matches entries
+// whose size in bytes is between min and max inclusive.
+// Pass -1 for min or max to leave that end unbounded.
+// Entries whose size cannot be read do not match.
+func MatchSize(min, max int64) FilterFunc {
+	return func(e Entry) bool {
+		n, ok := size(e)
+		return ok && (min < 0 || n >= min) && (max < 0 || n <= max)
+	}
+}
+
+// MatchLargerThan returns a FilterFunc that matches entries
+// larger than bytes.
+func MatchLargerThan(bytes int64) FilterFunc {
+	return func(e Entry) bool {
+		n, ok := size(e)
+		return ok && n > bytes
+	}
+}
+
+// MatchSmallerThan returns a FilterFunc that matches entries
+// smaller than bytes.
+func MatchSmallerThan(bytes int64) FilterFunc {
+	if bytes <= 0 {
+		return func(Entry) bool { return false }
+	}
+	return MatchSize(-1, bytes-1)
+}
+
+// MatchMinSize returns a FilterFunc that matches entries
+// whose size is at least n bytes.
+// Entries whose size cannot be read do not match; see RequireInfo.
+func MatchMinSize(n int64) FilterFunc {
+	return MatchSize(max(n, 0), -1)
+}
+
+// MatchMaxSize returns a FilterFunc that matches entries
+// whose size is at most n bytes.
+// Entries whose size cannot be read do not match; see RequireInfo.
+func MatchMaxSize(n int64) FilterFunc {
+	if n < 0 {
+		return func(Entry) bool { return false }
+	}
+	return MatchSize(-1, n)
+}
+
+// TopNBySize walks the tree and returns the n largest matching files,
+// largest first, along with the Ranger's Err().
+// Files of equal size are ordered by path,
+// so ties at the cutoff are broken the same way on every walk.
+// Files whose size cannot be read are treated as having size zero.
+// All matching files are held in memory until the walk is done.
+func (tr *Ranger) TopNBySize(n int) ([]Entry, error) {
+	files := tr.bySize()
+	return files[:min(max(n, 0), len(files))], tr.Err()
+}
+
+// TopBySize is like TopNBySize,
+// but returns the given fraction of the matching files, from 0 to 1,
+// rounded up, so 0.1 returns the largest 10%.
+func (tr *Ranger) TopBySize(fraction float64) ([]Entry, error) {
+	files := tr.bySize()
+	// Allow for rounding error, so 10% of 30 files is 3, not 4.
+	n := int(math.Ceil(fraction*float64(len(files)) - 1e-9))
+	return files[:min(max(n, 0), len(files))], tr.Err()
+}
+
+// bySize collects the matching files, sorted largest first and then by path.
+func (tr *Ranger) bySize() []Entry {
+	files := slices.Collect(tr.FileEntries())
+	slices.SortFunc(files, func(a, b Entry) int {
+		if c := CompareBySize(b, a); c != 0 {
+			return c
+		}
+		return CompareByPath(a, b)
+	})
+	return files
+}
+
+var sizeUnits = map[string]float64{
+	"":    1,
+	"b":   1,
+	"k":   1 << 10,
+	"kb":  1e3,
+	"kib": 1 << 10,
+	"m":   1 << 20,
+	"mb":  1e6,
+	"mib": 1 << 20,
+	"g":   1 << 30,
+	"gb":  1e9,
+	"gib": 1 << 30,
+	"t":   1 << 40,
+	"tb":  1e12,
+	"tib": 1 << 40,
+}
+
+// ParseSize parses a human readable size such as "10MB" or "1.5 GiB"
+// into a number of bytes.
+// Units are case insensitive.
+// KB, MB, GB, and TB are powers of 1000;
+// KiB, MiB, GiB, and TiB and the single letters K, M, G, and T are powers of 1024.
+// A number without a unit is a count of bytes.
+// Sizes too large for an int64 return an error wrapping strconv.ErrRange.
+func ParseSize(s string) (int64, error) {
+	s = strings.TrimSpace(s)
+	i := strings.LastIndexAny(s, "0123456789.") + 1
+	num, unit := s[:i], strings.ToLower(strings.TrimSpace(s[i:]))
+	mult, ok := sizeUnits[unit]
+	if !ok {
+		return 0, fmt.Errorf("walker: invalid size %q: unknown unit %q", s, unit)
+	}
+	n, err := strconv.ParseFloat(num, 64)
+	if err != nil || n < 0 {
+		return 0, fmt.Errorf("walker: invalid size %q", s)
+	}
+	// float64(math.MaxInt64) rounds up to 1<<63, which does not fit.
+	if n*mult >= math.MaxInt64 {
+		return 0, fmt.Errorf("walker: invalid size %q: %w", s, strconv.ErrRange)
+	}
+	return int64(n * mult), nil
+}
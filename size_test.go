@@ -0,0 +1,175 @@
+package walker_test
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"math"
+	"slices"
+	"strconv"
+	"strings"
+	"testing"
+	"testing/fstest"
+	"time"
+
+	"github.com/carlmjohnson/be"
+	"github.com/earthboundkid/walker"
+)
+
+func TestMatchSize(t *testing.T) {
+	testFS := fstest.MapFS{
+		"empty.txt": &fstest.MapFile{},
+		"small.txt": &fstest.MapFile{Data: make([]byte, 10)},
+		"large.txt": &fstest.MapFile{Data: make([]byte, 100)},
+	}
+	for _, tc := range []struct {
+		name   string
+		filter walker.FilterFunc
+		want   string
+	}{
+		{"size", walker.MatchSize(1, 10), "small.txt"},
+		{"size unbounded", walker.MatchSize(-1, -1), "empty.txt; large.txt; small.txt"},
+		{"larger", walker.MatchLargerThan(10), "large.txt"},
+		{"larger 0", walker.MatchLargerThan(0), "large.txt; small.txt"},
+		{"larger max", walker.MatchLargerThan(math.MaxInt64), ""},
+		{"larger negative", walker.MatchLargerThan(-1), "empty.txt; large.txt; small.txt"},
+		{"smaller", walker.MatchSmallerThan(100), "empty.txt; small.txt"},
+		{"smaller 0", walker.MatchSmallerThan(0), ""},
+		{"min", walker.MatchMinSize(10), "large.txt; small.txt"},
+		{"min 0", walker.MatchMinSize(0), "empty.txt; large.txt; small.txt"},
+		{"max", walker.MatchMaxSize(10), "empty.txt; small.txt"},
+		{"max negative", walker.MatchMaxSize(-1), ""},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			tr := walker.New(testFS, ".", walker.OnErrorHalt)
+			tr.Include(tc.filter)
+			paths := slices.Collect(tr.FilePaths())
+			be.NilErr(t, tr.Err())
+			be.Equal(t, tc.want, strings.Join(paths, "; "))
+		})
+	}
+}
+
+func TestParseSize(t *testing.T) {
+	for _, tc := range []struct {
+		in   string
+		want int64
+	}{
+		{"0", 0},
+		{"512", 512},
+		{"512B", 512},
+		{"10KB", 10_000},
+		{"10kib", 10 << 10},
+		{"10K", 10 << 10},
+		{"10MB", 10_000_000},
+		{"1.5 GiB", 3 << 29},
+		{" 2tb ", 2_000_000_000_000},
+	} {
+		got, err := walker.ParseSize(tc.in)
+		be.NilErr(t, err)
+		be.Equal(t, tc.want, got)
+	}
+	for _, in := range []string{"", "MB", "10XB", "ten", "-5MB", "1..5MB"} {
+		_, err := walker.ParseSize(in)
+		be.Nonzero(t, err)
+	}
+	for _, in := range []string{"9999999999T", "8388608 TiB", "9223372036854775808"} {
+		_, err := walker.ParseSize(in)
+		be.True(t, errors.Is(err, strconv.ErrRange))
+	}
+}
+
+func TestRanger_TopBySize(t *testing.T) {
+	testFS := fstest.MapFS{}
+	for i := range 20 {
+		testFS[fmt.Sprintf("dir/file%02d.txt", i)] = &fstest.MapFile{Data: make([]byte, i*10)}
+	}
+	names := func(entries []walker.Entry) string {
+		var paths []string
+		for _, e := range entries {
+			paths = append(paths, e.Path)
+		}
+		return strings.Join(paths, "; ")
+	}
+
+	tr := walker.New(testFS, ".", walker.OnErrorHalt)
+	top, err := tr.TopBySize(0.1)
+	be.NilErr(t, err)
+	be.Equal(t, "dir/file19.txt; dir/file18.txt", names(top))
+
+	top, err = tr.TopBySize(0)
+	be.NilErr(t, err)
+	be.Equal(t, "", names(top))
+
+	top, err = tr.TopBySize(0.15)
+	be.NilErr(t, err)
+	be.Equal(t, "dir/file19.txt; dir/file18.txt; dir/file17.txt", names(top))
+
+	testFS["dir/tie.txt"] = &fstest.MapFile{Data: make([]byte, 180)}
+	top, err = tr.TopNBySize(2)
+	be.NilErr(t, err)
+	be.Equal(t, "dir/file19.txt; dir/file18.txt", names(top))
+	top, err = tr.TopNBySize(3)
+	be.NilErr(t, err)
+	be.Equal(t, "dir/file19.txt; dir/file18.txt; dir/tie.txt", names(top))
+
+	top, err = tr.TopNBySize(100)
+	be.NilErr(t, err)
+	be.Equal(t, 21, len(top))
+}
+
+// infoDirEntry is a DirEntry which counts calls to Info
+// and fails for names beginning with "bad".
+type infoDirEntry struct {
+	name  string
+	calls *int
+}
+
+func (d infoDirEntry) Name() string      { return d.name }
+func (d infoDirEntry) IsDir() bool       { return d.name == "root" }
+func (d infoDirEntry) Type() fs.FileMode { return 0 }
+
+func (d infoDirEntry) Info() (fs.FileInfo, error) {
+	*d.calls++
+	if strings.HasPrefix(d.name, "bad") {
+		return nil, fs.ErrPermission
+	}
+	return fstest.MapFS{d.name: &fstest.MapFile{
+		Data:    make([]byte, 10),
+		ModTime: time.Date(2024, time.June, 2, 0, 0, 0, 0, time.UTC),
+	}}.Stat(d.name)
+}
+
+func TestRequireInfo(t *testing.T) {
+	calls := 0
+	readDir := func(string) ([]fs.DirEntry, error) {
+		return []fs.DirEntry{
+			infoDirEntry{"a.txt", &calls},
+			infoDirEntry{"bad.txt", &calls},
+			infoDirEntry{"b.txt", &calls},
+		}, nil
+	}
+	filter := walker.And(
+		walker.MatchMinSize(5),
+		walker.MatchMaxSize(20),
+		walker.MatchModifiedAfter(time.Date(2024, time.June, 1, 0, 0, 0, 0, time.UTC)),
+		walker.MatchModifiedBefore(time.Date(2024, time.June, 3, 0, 0, 0, 0, time.UTC)),
+	)
+
+	tr := walker.NewFunc(readDir, "root", walker.OnErrorHalt)
+	tr.Include(filter)
+	paths := slices.Collect(tr.FilePaths())
+	be.NilErr(t, tr.Err())
+	be.Equal(t, "root/a.txt; root/b.txt", strings.Join(paths, "; "))
+	// Info is read once per file, however many filters use it.
+	be.Equal(t, 3, calls)
+
+	var errs []error
+	tr = walker.NewFunc(readDir, "root", walker.OnErrorCollect(&errs))
+	tr.IncludeErr(walker.RequireInfo(filter))
+	paths = slices.Collect(tr.FilePaths())
+	be.NilErr(t, tr.Err())
+	be.Equal(t, "root/a.txt; root/b.txt", strings.Join(paths, "; "))
+	be.Equal(t, 1, len(errs))
+	be.True(t, errors.Is(errs[0], fs.ErrPermission))
+}
@@ -0,0 +1,80 @@
+package walker
+
+import (
+	"io/fs"
+	"path/filepath"
+	"time"
+)
+
+// FileState is the size and modification time of a file,
+// as recorded by Snapshot.
+type FileState struct {
+	Size    int64     `json:"size"`
+	ModTime time.Time `json:"mod_time"`
+}
+
+// Snapshot walks the tree and returns the state of each matching file,
+// ignoring directories,
+// keyed by its slash separated path relative to the root of the walk.
+// The snapshot may be persisted, such as with encoding/json,
+// and later passed to MatchChangedFrom.
+// Failures to read a file's info are passed to the ErrorPolicy,
+// and if it halts, Snapshot stops and returns the failure.
+// Otherwise, it returns the Ranger's Err().
+func (tr *Ranger) Snapshot() (map[string]FileState, error) {
+	snap := make(map[string]FileState)
+	for e := range tr.FileEntries() {
+		info, err := e.DirEntry.Info()
+		if err != nil {
+			if !tr.handleError(err, e) {
+				tr.lastErr, tr.errEntry = err, e
+				return snap, err
+			}
+			continue
+		}
+		snap[filepath.ToSlash(e.RelRoot())] = FileState{info.Size(), info.ModTime()}
+	}
+	return snap, tr.Err()
+}
+
+// StatAll walks the tree and returns the fs.FileInfo of each matching file,
+// ignoring directories, keyed by Entry.Path.
+// Failures to read a file's info are passed to the ErrorPolicy
+// and the file is omitted, and if the policy halts,
+// StatAll stops and returns the failure.
+// Otherwise, it returns the Ranger's Err().
+func (tr *Ranger) StatAll() (map[string]fs.FileInfo, error) {
+	infos := make(map[string]fs.FileInfo)
+	for e := range tr.FileEntries() {
+		info, err := e.DirEntry.Info()
+		if err != nil {
+			if !tr.handleError(err, e) {
+				tr.lastErr, tr.errEntry = err, e
+				return infos, err
+			}
+			continue
+		}
+		infos[e.Path] = info
+	}
+	return infos, tr.Err()
+}
+
+// MatchChangedFrom returns a FilterFunc that matches files
+// which are not in snap or whose size or modification time
+// differs from the FileState recorded by Snapshot.
+// Files are looked up by their slash separated path relative to the root of the walk,
+// so the Ranger should have the same root as the one that took the snapshot.
+// Directories and files whose info cannot be read do not match.
+func MatchChangedFrom(snap map[string]FileState) FilterFunc {
+	return func(e Entry) bool {
+		if e.DirEntry == nil || e.IsDir() {
+			return false
+		}
+		info, err := e.DirEntry.Info()
+		if err != nil {
+			return false
+		}
+		old, ok := snap[filepath.ToSlash(e.RelRoot())]
+		return !ok || old.Size != info.Size() || !old.ModTime.Equal(info.ModTime())
+	}
+}
@@ -0,0 +1,115 @@
+package walker_test
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"maps"
+	"slices"
+	"strings"
+	"testing"
+	"testing/fstest"
+	"time"
+
+	"github.com/carlmjohnson/be"
+	"github.com/earthboundkid/walker"
+)
+
+func TestRanger_Snapshot(t *testing.T) {
+	then := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	testFS := fstest.MapFS{
+		"a.txt":          {Data: []byte("a"), ModTime: then},
+		"dir1/file3.txt": {Data: []byte("b"), ModTime: then},
+		"dir1/file4.txt": {Data: []byte("c"), ModTime: then},
+	}
+	tr := walker.New(testFS, ".", walker.OnErrorHalt)
+	snap, err := tr.Snapshot()
+	be.NilErr(t, err)
+	be.Equal(t, 3, len(snap))
+	be.Equal(t, walker.FileState{Size: 1, ModTime: then}, snap["dir1/file3.txt"])
+
+	// Snapshots survive a round trip through JSON.
+	data, err := json.Marshal(snap)
+	be.NilErr(t, err)
+	snap = nil
+	be.NilErr(t, json.Unmarshal(data, &snap))
+
+	tr.Include(walker.MatchChangedFrom(snap))
+	paths := slices.Collect(tr.FilePaths())
+	be.Equal(t, 0, len(paths))
+
+	testFS["dir1/file3.txt"].ModTime = then.Add(time.Second)
+	paths = slices.Collect(tr.FilePaths())
+	be.Equal(t, "dir1/file3.txt", strings.Join(paths, "; "))
+
+	testFS["a.txt"].Data = []byte("aa")
+	testFS["new.txt"] = &fstest.MapFile{ModTime: then}
+	paths = slices.Collect(tr.FilePaths())
+	be.Equal(t, "a.txt; dir1/file3.txt; new.txt", strings.Join(paths, "; "))
+
+	calls := 0
+	readDir := func(string) ([]fs.DirEntry, error) {
+		return []fs.DirEntry{infoDirEntry{"a.txt", &calls}, infoDirEntry{"bad.txt", &calls}}, nil
+	}
+	tr = walker.NewFunc(readDir, "root", walker.OnErrorHalt)
+	_, err = tr.Snapshot()
+	be.True(t, errors.Is(err, fs.ErrPermission))
+	be.Equal(t, err, tr.Err())
+	be.Equal(t, "root/bad.txt", tr.ErrPath())
+
+	// A failure on the last file is not returned if the policy continues.
+	var errs []error
+	tr = walker.NewFunc(readDir, "root", walker.OnErrorCollect(&errs))
+	snap, err = tr.Snapshot()
+	be.NilErr(t, err)
+	be.NilErr(t, tr.Err())
+	be.Equal(t, 1, len(errs))
+	be.Equal(t, "a.txt", strings.Join(slices.Collect(maps.Keys(snap)), "; "))
+}
+
+func TestRanger_StatAll(t *testing.T) {
+	then := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	testFS := fstest.MapFS{
+		"a.txt":                {Data: []byte("a"), ModTime: then},
+		"dir1/file3.txt":       {Data: []byte("bb"), ModTime: then.Add(time.Hour)},
+		"dir1/file4.log":       {Data: []byte("ccc"), Mode: 0o600},
+		"dir2/subdir/file6.go": {Data: []byte("dddd")},
+	}
+	tr := walker.New(testFS, ".", walker.OnErrorHalt)
+	tr.Exclude(walker.MatchExtension(".go"))
+	infos, err := tr.StatAll()
+	be.NilErr(t, err)
+	var got []string
+	for _, p := range slices.Sorted(maps.Keys(infos)) {
+		info := infos[p]
+		got = append(got, fmt.Sprintf("%s %s %d %v %s",
+			p, info.Name(), info.Size(), info.Mode(), info.ModTime().Format(time.Kitchen)))
+	}
+	be.Equal(t, ""+
+		"a.txt a.txt 1 ---------- 12:00PM; "+
+		"dir1/file3.txt file3.txt 2 ---------- 1:00PM; "+
+		"dir1/file4.log file4.log 3 -rw------- 12:00AM",
+		strings.Join(got, "; "))
+
+	calls := 0
+	readDir := func(string) ([]fs.DirEntry, error) {
+		return []fs.DirEntry{infoDirEntry{"bad.txt", &calls}, infoDirEntry{"b.txt", &calls}}, nil
+	}
+	tr = walker.NewFunc(readDir, "root", walker.OnErrorHalt)
+	infos, err = tr.StatAll()
+	be.True(t, errors.Is(err, fs.ErrPermission))
+	be.Equal(t, err, tr.Err())
+	be.Equal(t, "root/bad.txt", tr.ErrPath())
+	be.Equal(t, 0, len(infos))
+
+	// A failure on the last file is not returned if the policy continues.
+	readDir = func(string) ([]fs.DirEntry, error) {
+		return []fs.DirEntry{infoDirEntry{"b.txt", &calls}, infoDirEntry{"bad.txt", &calls}}, nil
+	}
+	tr = walker.NewFunc(readDir, "root", walker.OnErrorIgnore)
+	infos, err = tr.StatAll()
+	be.NilErr(t, err)
+	be.NilErr(t, tr.Err())
+	be.Equal(t, "root/b.txt", strings.Join(slices.Collect(maps.Keys(infos)), "; "))
+}
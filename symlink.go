@@ -0,0 +1,114 @@
+package walker
+
+import (
+	"errors"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+)
+
+// MatchTargetExtension returns a FilterFunc like MatchExtension,
+// except that for symbolic links
+// it checks the extension of the file the link resolves to.
+// Links that cannot be resolved do not match.
+// Resolving links is only supported when walking the OS filesystem;
+// for an fs.FS, links are matched by their own extension.
+func MatchTargetExtension(exts ...string) FilterFunc {
+	match := MatchExtension(exts...)
+	return func(e Entry) bool {
+		if e.useFilepath && e.IsSymlink() {
+			target, err := filepath.EvalSymlinks(e.Path)
+			if err != nil {
+				return false
+			}
+			e.Path = target
+		}
+		return match(e)
+	}
+}
+
+// MatchBrokenSymlink returns a FilterFunc that matches symbolic links
+// whose target does not exist.
+// When walking an fs.FS, the target is checked with fs.Stat,
+// so links are only detected if the fs.FS reports them in DirEntry.Type
+// and follows them in Stat, as os.DirFS does.
+// Entries from a NewFunc Ranger never match.
+func MatchBrokenSymlink() FilterFunc {
+	return func(e Entry) bool {
+		if !e.IsSymlink() {
+			return false
+		}
+		var err error
+		switch {
+		case e.useFilepath:
+			_, err = os.Stat(e.Path)
+		case e.fsys != nil:
+			_, err = fs.Stat(e.fsys, e.Path)
+		default:
+			return false
+		}
+		return errors.Is(err, fs.ErrNotExist)
+	}
+}
+
+// MatchUnderSymlink returns a FilterFunc that matches entries
+// with an ancestor directory, up to and including the root of the walk,
+// that is a symbolic link,
+// such as files reached through a link that may point outside the tree.
+// The entry itself is not checked; see Entry.IsSymlink.
+// Ancestors are checked with os.Lstat when walking the OS filesystem.
+// An fs.FS is only checked if it has a method
+// Lstat(name string) (fs.FileInfo, error), as os.DirFS does in newer versions of Go;
+// otherwise, like entries from a NewFunc Ranger, nothing matches.
+// Whether each directory is under a symbolic link is cached
+// for the life of the FilterFunc.
+func MatchUnderSymlink() FilterFunc {
+	cache := make(map[string]bool)
+	var under func(e Entry, dir string) bool
+	under = func(e Entry, dir string) bool {
+		if linked, ok := cache[dir]; ok {
+			return linked
+		}
+		linked := isSymlinkPath(e, dir)
+		if !linked && dir != e.root {
+			var parent string
+			if e.useFilepath {
+				parent = filepath.Dir(dir)
+			} else {
+				parent = path.Dir(dir)
+			}
+			linked = parent != dir && under(e, parent)
+		}
+		cache[dir] = linked
+		return linked
+	}
+	return func(e Entry) bool {
+		if e.Path == e.root {
+			return false
+		}
+		return under(e, e.parent())
+	}
+}
+
+// isSymlinkPath reports whether name, in the filesystem that produced e,
+// is a symbolic link.
+func isSymlinkPath(e Entry, name string) bool {
+	var info fs.FileInfo
+	var err error
+	switch {
+	case e.useFilepath:
+		info, err = os.Lstat(name)
+	case e.fsys != nil:
+		lfs, ok := e.fsys.(interface {
+			Lstat(name string) (fs.FileInfo, error)
+		})
+		if !ok {
+			return false
+		}
+		info, err = lfs.Lstat(name)
+	default:
+		return false
+	}
+	return err == nil && info.Mode()&fs.ModeSymlink != 0
+}
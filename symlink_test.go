@@ -0,0 +1,80 @@
+package walker_test
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+	"testing/fstest"
+
+	"github.com/carlmjohnson/be"
+	"github.com/earthboundkid/walker"
+)
+
+func TestMatchTargetExtension(t *testing.T) {
+	dir := t.TempDir()
+	be.NilErr(t, os.WriteFile(filepath.Join(dir, "data.json"), nil, 0o644))
+	be.NilErr(t, os.WriteFile(filepath.Join(dir, "other.txt"), nil, 0o644))
+	be.NilErr(t, os.Symlink("data.json", filepath.Join(dir, "link")))
+	be.NilErr(t, os.Symlink("missing.json", filepath.Join(dir, "broken")))
+
+	tr := walker.New(nil, dir, walker.OnErrorHalt)
+	tr.Include(walker.MatchTargetExtension(".json"))
+	var names []string
+	for path := range tr.FilePaths() {
+		names = append(names, filepath.Base(path))
+	}
+	be.NilErr(t, tr.Err())
+	be.Equal(t, "data.json; link", strings.Join(names, "; "))
+}
+
+func TestMatchBrokenSymlink(t *testing.T) {
+	dir := t.TempDir()
+	be.NilErr(t, os.WriteFile(filepath.Join(dir, "data.json"), nil, 0o644))
+	be.NilErr(t, os.WriteFile(filepath.Join(dir, "deleted.txt"), nil, 0o644))
+	be.NilErr(t, os.Symlink("data.json", filepath.Join(dir, "link")))
+	be.NilErr(t, os.Symlink("deleted.txt", filepath.Join(dir, "broken")))
+	be.NilErr(t, os.Remove(filepath.Join(dir, "deleted.txt")))
+
+	for _, tr := range []walker.Ranger{
+		walker.New(nil, dir, walker.OnErrorHalt),
+		walker.New(os.DirFS(dir), ".", walker.OnErrorHalt),
+	} {
+		tr.Include(walker.MatchBrokenSymlink())
+		var names []string
+		for path := range tr.FilePaths() {
+			names = append(names, filepath.Base(path))
+		}
+		be.NilErr(t, tr.Err())
+		be.Equal(t, "broken", strings.Join(names, "; "))
+	}
+}
+
+func TestMatchUnderSymlink(t *testing.T) {
+	fsys := followFS{
+		MapFS: fstest.MapFS{
+			"real/file.txt":          &fstest.MapFile{},
+			"sub/link/file.txt":      &fstest.MapFile{},
+			"sub/link/deep/file.txt": &fstest.MapFile{},
+			"sub/other.txt":          &fstest.MapFile{},
+		},
+		links: map[string]bool{"sub/link": true},
+	}
+	tr := walker.New(fsys, ".", walker.OnErrorHalt)
+	tr.Include(walker.MatchUnderSymlink())
+	paths := slices.Collect(tr.Paths())
+	be.NilErr(t, tr.Err())
+	be.Equal(t, "sub/link/deep; sub/link/deep/file.txt; sub/link/file.txt", strings.Join(paths, "; "))
+
+	// The root counts as an ancestor.
+	tr = walker.New(fsys, "sub/link", walker.OnErrorHalt)
+	tr.Include(walker.MatchUnderSymlink())
+	paths = slices.Collect(tr.FilePaths())
+	be.Equal(t, "sub/link/deep/file.txt; sub/link/file.txt", strings.Join(paths, "; "))
+
+	// Without Lstat, nothing matches.
+	tr = walker.New(fsys.MapFS, ".", walker.OnErrorHalt)
+	tr.Include(walker.MatchUnderSymlink())
+	be.Equal(t, 0, len(slices.Collect(tr.Paths())))
+}
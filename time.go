@@ -0,0 +1,95 @@
+package walker
+
+import "time"
+
+// MatchAccessedBefore returns a FilterFunc that matches entries
+// last accessed before t.
+// Access times come from the underlying syscall.Stat_t
+// on Linux, macOS, and the BSDs.
+// On other platforms, or for filesystems that do not expose access times,
+// the modification time is used instead.
+// Note that many systems mount filesystems with options
+// that limit how often access times are updated.
+func MatchAccessedBefore(t time.Time) FilterFunc {
+	return func(e Entry) bool {
+		atime, ok := accessTime(e)
+		return ok && atime.Before(t)
+	}
+}
+
+// MatchAccessedAfter returns a FilterFunc that matches entries
+// last accessed after t.
+// See MatchAccessedBefore for platform caveats.
+func MatchAccessedAfter(t time.Time) FilterFunc {
+	return func(e Entry) bool {
+		atime, ok := accessTime(e)
+		return ok && atime.After(t)
+	}
+}
+
+// MatchModifiedAfter returns a FilterFunc that matches entries
+// last modified after t.
+// Entries whose modification time cannot be read do not match; see RequireInfo.
+func MatchModifiedAfter(t time.Time) FilterFunc {
+	return func(e Entry) bool {
+		mtime, ok := modTime(e)
+		return ok && mtime.After(t)
+	}
+}
+
+// MatchModifiedBefore returns a FilterFunc that matches entries
+// last modified before t.
+// Entries whose modification time cannot be read do not match; see RequireInfo.
+func MatchModifiedBefore(t time.Time) FilterFunc {
+	return func(e Entry) bool {
+		mtime, ok := modTime(e)
+		return ok && mtime.Before(t)
+	}
+}
+
+// MatchModifiedOn returns a FilterFunc that matches entries
+// last modified on the same calendar day as day in the location loc.
+// The day is taken to run from midnight to the following midnight in loc,
+// so days lengthened or shortened by daylight saving time are handled correctly.
+// If loc is nil, time.Local is used.
+// Entries whose modification time cannot be read do not match.
+func MatchModifiedOn(day time.Time, loc *time.Location) FilterFunc {
+	if loc == nil {
+		loc = time.Local
+	}
+	y, m, d := day.In(loc).Date()
+	start := time.Date(y, m, d, 0, 0, 0, 0, loc)
+	end := time.Date(y, m, d+1, 0, 0, 0, 0, loc)
+	return func(e Entry) bool {
+		t, ok := modTime(e)
+		return ok && !t.Before(start) && t.Before(end)
+	}
+}
+
+// MatchCreatedBefore returns a FilterFunc that matches entries
+// created before t.
+// Creation times come from the underlying syscall.Stat_t
+// on macOS, FreeBSD, and NetBSD,
+// from the file attributes on Windows,
+// and from statx on Linux, where they are only available
+// when walking the OS filesystem rather than an fs.FS.
+// On other platforms, where creation times
+// are not available through package syscall,
+// or for filesystems that do not expose them,
+// nothing matches.
+func MatchCreatedBefore(t time.Time) FilterFunc {
+	return func(e Entry) bool {
+		btime, ok := birthTime(e)
+		return ok && btime.Before(t)
+	}
+}
+
+// MatchCreatedAfter returns a FilterFunc that matches entries
+// created after t.
+// See MatchCreatedBefore for platform caveats.
+func MatchCreatedAfter(t time.Time) FilterFunc {
+	return func(e Entry) bool {
+		btime, ok := birthTime(e)
+		return ok && btime.After(t)
+	}
+}
@@ -0,0 +1,98 @@
+package walker_test
+
+import (
+	"os"
+	"path/filepath"
+	"runtime"
+	"slices"
+	"strings"
+	"testing"
+	"testing/fstest"
+	"time"
+
+	"github.com/carlmjohnson/be"
+	"github.com/earthboundkid/walker"
+)
+
+func TestMatchModifiedOn(t *testing.T) {
+	nyc, err := time.LoadLocation("America/New_York")
+	if err != nil {
+		t.Skip("time zone database not available")
+	}
+	at := func(month time.Month, day, hour, min int) *fstest.MapFile {
+		return &fstest.MapFile{ModTime: time.Date(2024, month, day, hour, min, 0, 0, nyc)}
+	}
+	testFS := fstest.MapFS{
+		"before-midnight.log": at(time.June, 1, 23, 59),
+		"midnight.log":        at(time.June, 2, 0, 0),
+		"noon.log":            at(time.June, 2, 12, 0),
+		"next-day.log":        at(time.June, 3, 0, 0),
+		// Daylight saving time begins on March 10 and ends on November 3.
+		"spring-late.log":  at(time.March, 10, 23, 30),
+		"spring-after.log": at(time.March, 11, 0, 30),
+		"fall-late.log":    at(time.November, 3, 23, 30),
+	}
+	for _, tc := range []struct {
+		day  time.Time
+		loc  *time.Location
+		want string
+	}{
+		{time.Date(2024, time.June, 2, 15, 0, 0, 0, nyc), nyc, "midnight.log; noon.log"},
+		// 2am UTC on June 2 is still June 1 in New York.
+		{time.Date(2024, time.June, 2, 2, 0, 0, 0, time.UTC), nyc, "before-midnight.log"},
+		{time.Date(2024, time.June, 2, 0, 0, 0, 0, time.UTC), time.UTC, "before-midnight.log; midnight.log; noon.log"},
+		{time.Date(2024, time.March, 10, 12, 0, 0, 0, nyc), nyc, "spring-late.log"},
+		{time.Date(2024, time.November, 3, 12, 0, 0, 0, nyc), nyc, "fall-late.log"},
+	} {
+		tr := walker.New(testFS, ".", walker.OnErrorHalt)
+		tr.Include(walker.MatchModifiedOn(tc.day, tc.loc))
+		paths := slices.Collect(tr.FilePaths())
+		be.NilErr(t, tr.Err())
+		be.Equal(t, tc.want, strings.Join(paths, "; "))
+	}
+}
+
+func TestMatchModifiedAfter(t *testing.T) {
+	at := func(day int) *fstest.MapFile {
+		return &fstest.MapFile{ModTime: time.Date(2024, time.June, day, 0, 0, 0, 0, time.UTC)}
+	}
+	testFS := fstest.MapFS{
+		"old.log":    at(1),
+		"middle.log": at(2),
+		"new.log":    at(3),
+	}
+	day2 := time.Date(2024, time.June, 2, 0, 0, 0, 0, time.UTC)
+	for _, tc := range []struct {
+		filter walker.FilterFunc
+		want   string
+	}{
+		{walker.MatchModifiedAfter(day2), "new.log"},
+		{walker.MatchModifiedBefore(day2), "old.log"},
+		{walker.Not(walker.MatchModifiedBefore(day2)), "middle.log; new.log"},
+	} {
+		tr := walker.New(testFS, ".", walker.OnErrorHalt)
+		tr.Include(tc.filter)
+		paths := slices.Collect(tr.FilePaths())
+		be.NilErr(t, tr.Err())
+		be.Equal(t, tc.want, strings.Join(paths, "; "))
+	}
+}
+
+func TestMatchCreated_unsupported(t *testing.T) {
+	if runtime.GOOS != "linux" {
+		t.Skip("creation times may be supported")
+	}
+	dir := t.TempDir()
+	be.NilErr(t, os.WriteFile(filepath.Join(dir, "new.txt"), nil, 0o644))
+	for _, match := range []walker.FilterFunc{
+		walker.MatchCreatedAfter(time.Time{}),
+		walker.MatchCreatedBefore(time.Now().Add(time.Hour)),
+	} {
+		// On Linux, creation times come from statx,
+		// which needs a path on the OS filesystem.
+		tr := walker.New(os.DirFS(dir), ".", walker.OnErrorHalt)
+		tr.Include(match)
+		be.Equal(t, 0, len(slices.Collect(tr.FilePaths())))
+		be.NilErr(t, tr.Err())
+	}
+}
@@ -0,0 +1,251 @@
+package walker
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+)
+
+// NewFunc creates a new Ranger like New
+// that reads directories by calling readDir instead of using an fs.FS or the OS filesystem.
+// The paths passed to readDir are slash separated,
+// beginning with root and joined with path.Join.
+// Entries are walked in the order readDir returns them,
+// unless StableOrder is set.
+// Because there is no underlying filesystem,
+// Entries from a NewFunc Ranger cannot be opened or read.
+func NewFunc(readDir func(path string) ([]fs.DirEntry, error), root string, erp ErrorPolicy) Ranger {
+	return Ranger{
+		readDir: readDir,
+		root:    root,
+		erp:     erp,
+	}
+}
+
+// useFilepath reports whether the Ranger walks the OS filesystem.
+func (tr *Ranger) useFilepath() bool {
+	return tr.fsys == nil && tr.readDir == nil
+}
+
+// readDirNamed reads the named directory using the Ranger's backend.
+// For an fs.FS, fs.ReadDir calls ReadDir directly
+// if the filesystem implements fs.ReadDirFS,
+// and only falls back to opening the directory and sorting its entries otherwise.
+func (tr *Ranger) readDirNamed(name string) ([]fs.DirEntry, error) {
+	switch {
+	case tr.readDir != nil:
+		return tr.readDir(name)
+	case tr.fsys != nil:
+		return fs.ReadDir(tr.fsys, name)
+	}
+	return os.ReadDir(name)
+}
+
+// join joins a directory and a name using the Ranger's path separator.
+func (tr *Ranger) join(dir, name string) string {
+	if tr.useFilepath() {
+		return filepath.Join(dir, name)
+	}
+	return path.Join(dir, name)
+}
+
+// statRoot returns a DirEntry for the root of the walk.
+func (tr *Ranger) statRoot() (fs.DirEntry, error) {
+	var info fs.FileInfo
+	var err error
+	switch {
+	case tr.readDir != nil:
+		info = rootInfo(path.Base(tr.root))
+	case tr.fsys != nil && !fs.ValidPath(tr.root):
+		err = fmt.Errorf("walker: invalid fs.FS root %q: %w", tr.root, fs.ErrInvalid)
+	case tr.fsys != nil:
+		info, err = fs.Stat(tr.fsys, tr.root)
+	default:
+		info, err = os.Lstat(tr.root)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return fs.FileInfoToDirEntry(info), nil
+}
+
+// walkRoot walks the tree from the root like fs.WalkDir and filepath.WalkDir.
+func (tr *Ranger) walkRoot(fn fs.WalkDirFunc) {
+	d, err := tr.statRoot()
+	if err != nil {
+		_ = fn(tr.root, nil, err)
+		return
+	}
+	tr.linkParents = nil
+	if tr.breadthFirst {
+		tr.walkLevels(tr.root, tr.followLink(tr.root, d), fn)
+		return
+	}
+	_ = tr.walkDir(tr.root, tr.followLink(tr.root, d), fn)
+}
+
+// queuedDir is a directory waiting to be read by walkLevels,
+// along with the linkParents of the walk where it was reached.
+type queuedDir struct {
+	name        string
+	d           fs.DirEntry
+	linkParents []string
+}
+
+// walkLevels walks the tree from name in level order, calling fn.
+// It follows the same calling conventions as walkDir,
+// but a directory is only queued to be read
+// if fn returns nil when the directory is visited.
+func (tr *Ranger) walkLevels(name string, d fs.DirEntry, fn fs.WalkDirFunc) {
+	if err := fn(name, d, nil); err != nil || !d.IsDir() {
+		return
+	}
+	queue := []queuedDir{{name: name, d: d}}
+	for len(queue) > 0 {
+		dir := queue[0]
+		queue[0] = queuedDir{}
+		queue = queue[1:]
+		// Clip so that enterLink appends to a copy
+		// instead of sharing the slice with the directory's siblings.
+		tr.linkParents = slices.Clip(dir.linkParents)
+		if dir.d.Type()&fs.ModeSymlink != 0 && tr.canFollow() {
+			if _, err := tr.enterLink(dir.name); err != nil {
+				if err := fn(dir.name, dir.d, err); err != nil && !errors.Is(err, fs.SkipDir) {
+					return
+				}
+				continue
+			}
+		}
+		dirs, err := tr.readDirNamed(dir.name)
+		if err != nil {
+			if err := fn(dir.name, dir.d, err); err != nil {
+				if errors.Is(err, fs.SkipDir) {
+					continue
+				}
+				return
+			}
+		}
+		if tr.stableOrder || tr.sortSiblings != nil {
+			dirs = sortedByName(dirs)
+		}
+		if tr.sortSiblings != nil {
+			dirs = tr.sortedBy(dir.name, dirs)
+		}
+		for _, d1 := range dirs {
+			name1 := tr.join(dir.name, d1.Name())
+			d1 = tr.followLink(name1, d1)
+			if err := fn(name1, d1, nil); err != nil {
+				if !errors.Is(err, fs.SkipDir) {
+					return
+				}
+				if d1.IsDir() {
+					continue
+				}
+				break
+			}
+			if d1.IsDir() {
+				queue = append(queue, queuedDir{name1, d1, tr.linkParents})
+			}
+		}
+	}
+}
+
+// walkDir recursively descends name, calling fn.
+// It follows the same calling conventions as fs.WalkDir.
+func (tr *Ranger) walkDir(name string, d fs.DirEntry, fn fs.WalkDirFunc) error {
+	if err := fn(name, d, nil); err != nil || !d.IsDir() {
+		if errors.Is(err, fs.SkipDir) && d.IsDir() {
+			err = nil
+		}
+		return err
+	}
+	if d.Type()&fs.ModeSymlink != 0 && tr.canFollow() {
+		leave, err := tr.enterLink(name)
+		if err != nil {
+			if err := fn(name, d, err); err != nil && !errors.Is(err, fs.SkipDir) {
+				return err
+			}
+			return nil
+		}
+		defer leave()
+	}
+	dirs, err := tr.readDirNamed(name)
+	if err != nil {
+		err = fn(name, d, err)
+		if err != nil {
+			if errors.Is(err, fs.SkipDir) && d.IsDir() {
+				err = nil
+			}
+			return err
+		}
+	}
+	if tr.stableOrder || tr.sortSiblings != nil {
+		dirs = sortedByName(dirs)
+	}
+	if tr.sortSiblings != nil {
+		dirs = tr.sortedBy(name, dirs)
+	}
+	for _, d1 := range dirs {
+		name1 := tr.join(name, d1.Name())
+		if err := tr.walkDir(name1, tr.followLink(name1, d1), fn); err != nil {
+			if errors.Is(err, fs.SkipDir) {
+				break
+			}
+			return err
+		}
+	}
+	return nil
+}
+
+// sortedByName returns dirs sorted by name.
+// A listing that is already sorted is returned as is,
+// and otherwise it is copied so a readDir's own slice is not modified.
+func sortedByName(dirs []fs.DirEntry) []fs.DirEntry {
+	byName := func(a, b fs.DirEntry) int {
+		return strings.Compare(a.Name(), b.Name())
+	}
+	if slices.IsSortedFunc(dirs, byName) {
+		return dirs
+	}
+	return slices.SortedFunc(slices.Values(dirs), byName)
+}
+
+// sortedBy returns a copy of dirs, the children of the directory name,
+// stably sorted by the Ranger's SortBy function.
+// The DirEntries are wrapped with cacheInfo,
+// so info read while sorting is not read again by the walk.
+func (tr *Ranger) sortedBy(name string, dirs []fs.DirEntry) []fs.DirEntry {
+	entries := make([]Entry, len(dirs))
+	for i, d := range dirs {
+		entries[i] = Entry{
+			Path:        tr.join(name, d.Name()),
+			DirEntry:    cacheInfo(d),
+			root:        tr.root,
+			fsys:        tr.fsys,
+			useFilepath: tr.useFilepath(),
+			openSem:     tr.openSem,
+		}
+	}
+	slices.SortStableFunc(entries, tr.sortSiblings)
+	sorted := make([]fs.DirEntry, len(entries))
+	for i, e := range entries {
+		sorted[i] = e.DirEntry
+	}
+	return sorted
+}
+
+// rootInfo is the fs.FileInfo of a root directory which cannot be stat'ed.
+type rootInfo string
+
+func (fi rootInfo) Name() string       { return string(fi) }
+func (fi rootInfo) Size() int64        { return 0 }
+func (fi rootInfo) Mode() fs.FileMode  { return fs.ModeDir | 0o555 }
+func (fi rootInfo) ModTime() time.Time { return time.Time{} }
+func (fi rootInfo) IsDir() bool        { return true }
+func (fi rootInfo) Sys() any           { return nil }
@@ -0,0 +1,296 @@
+package walker_test
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"math/rand/v2"
+	"slices"
+	"strings"
+	"testing"
+	"testing/fstest"
+	"time"
+
+	"github.com/carlmjohnson/be"
+	"github.com/earthboundkid/walker"
+)
+
+// memInfo is a minimal fs.FileInfo for in-memory trees.
+type memInfo struct {
+	name  string
+	isDir bool
+}
+
+func (fi memInfo) Name() string { return fi.name }
+func (fi memInfo) Size() int64  { return 0 }
+func (fi memInfo) Mode() fs.FileMode {
+	if fi.isDir {
+		return fs.ModeDir | 0o755
+	}
+	return 0o644
+}
+func (fi memInfo) ModTime() time.Time { return time.Time{} }
+func (fi memInfo) IsDir() bool        { return fi.isDir }
+func (fi memInfo) Sys() any           { return nil }
+
+// memReadDir returns a readDir function for a tree of directory names to child names.
+// Child names ending in a slash are directories.
+func memReadDir(tree map[string][]string) func(string) ([]fs.DirEntry, error) {
+	return func(name string) ([]fs.DirEntry, error) {
+		children, ok := tree[name]
+		if !ok {
+			return nil, &fs.PathError{Op: "readdir", Path: name, Err: fs.ErrNotExist}
+		}
+		var entries []fs.DirEntry
+		for _, child := range children {
+			child, isDir := strings.CutSuffix(child, "/")
+			entries = append(entries, fs.FileInfoToDirEntry(memInfo{child, isDir}))
+		}
+		return entries, nil
+	}
+}
+
+// shuffledFS is an fs.ReadDirFS which lists directories in random order.
+type shuffledFS struct {
+	fstest.MapFS
+}
+
+func (fsys shuffledFS) ReadDir(name string) ([]fs.DirEntry, error) {
+	entries, err := fsys.MapFS.ReadDir(name)
+	rand.Shuffle(len(entries), func(i, j int) {
+		entries[i], entries[j] = entries[j], entries[i]
+	})
+	return entries, err
+}
+
+func TestNewFunc(t *testing.T) {
+	readDir := memReadDir(map[string][]string{
+		"root":             {"a.txt", "dir1/", "dir2/", "file1.txt"},
+		"root/dir1":        {"file3.txt", "file4.log"},
+		"root/dir2":        {"file5.txt", "subdir/"},
+		"root/dir2/subdir": {"file6.go"},
+	})
+	tr := walker.NewFunc(readDir, "root", walker.OnErrorHalt)
+	be.NilErr(t, tr.Validate())
+	var paths []string
+	for e := range tr.Entries() {
+		paths = append(paths, e.Path)
+	}
+	be.NilErr(t, tr.Err())
+	be.Equal(t, "root; root/a.txt; root/dir1; root/dir1/file3.txt; root/dir1/file4.log; "+
+		"root/dir2; root/dir2/file5.txt; root/dir2/subdir; root/dir2/subdir/file6.go; root/file1.txt",
+		strings.Join(paths, "; "))
+
+	tr.Include(walker.MatchExtension(".txt"))
+	tr.ExcludeDir(walker.MatchGlobName("dir2"))
+	paths = slices.Collect(tr.FilePaths())
+	be.NilErr(t, tr.Err())
+	be.Equal(t, "root/a.txt; root/dir1/file3.txt; root/file1.txt", strings.Join(paths, "; "))
+
+	for e := range tr.FileEntries() {
+		_, err := e.WriteTo(new(strings.Builder))
+		be.True(t, errors.Is(err, errors.ErrUnsupported))
+	}
+
+	tr = walker.NewFunc(readDir, "missing", walker.OnErrorHalt)
+	be.True(t, errors.Is(tr.Validate(), fs.ErrNotExist))
+	paths = slices.Collect(tr.FilePaths())
+	be.Equal(t, 0, len(paths))
+	be.True(t, errors.Is(tr.Err(), fs.ErrNotExist))
+}
+
+func TestRanger_DedupPaths(t *testing.T) {
+	// Simulate merging two overlapping trees,
+	// which lists some entries twice.
+	readDir := memReadDir(map[string][]string{
+		"root":        {"a.txt", "shared/", "a.txt", "shared/", "b.txt"},
+		"root/shared": {"c.txt", "d.txt", "c.txt"},
+	})
+	tr := walker.NewFunc(readDir, "root", walker.OnErrorHalt)
+	paths := slices.Collect(tr.FilePaths())
+	be.NilErr(t, tr.Err())
+	be.Equal(t, 9, len(paths))
+
+	tr.DedupPaths()
+	for range 2 {
+		paths = slices.Collect(tr.Paths())
+		be.NilErr(t, tr.Err())
+		be.Equal(t, "root; root/a.txt; root/shared; root/shared/c.txt; root/shared/d.txt; root/b.txt",
+			strings.Join(paths, "; "))
+	}
+}
+
+func TestRanger_StableOrder(t *testing.T) {
+	testFS := shuffledFS{fstest.MapFS{
+		"a.txt":                &fstest.MapFile{},
+		"b.txt":                &fstest.MapFile{},
+		"dir1/file3.txt":       &fstest.MapFile{},
+		"dir1/file4.txt":       &fstest.MapFile{},
+		"dir2/file5.txt":       &fstest.MapFile{},
+		"dir2/subdir/file6.go": &fstest.MapFile{},
+		"dir2/subdir/file7.go": &fstest.MapFile{},
+		"file1.txt":            &fstest.MapFile{},
+	}}
+	tr := walker.New(testFS, ".", walker.OnErrorHalt)
+	tr.StableOrder(true)
+	for range 10 {
+		paths := slices.Collect(tr.Paths())
+		be.NilErr(t, tr.Err())
+		be.Equal(t, ".; a.txt; b.txt; dir1; dir1/file3.txt; dir1/file4.txt; "+
+			"dir2; dir2/file5.txt; dir2/subdir; dir2/subdir/file6.go; dir2/subdir/file7.go; file1.txt",
+			strings.Join(paths, "; "))
+	}
+}
+
+func TestRanger_SortBy(t *testing.T) {
+	sized := func(n int, day int) *fstest.MapFile {
+		return &fstest.MapFile{
+			Data:    make([]byte, n),
+			ModTime: time.Date(2024, time.June, day, 0, 0, 0, 0, time.UTC),
+		}
+	}
+	testFS := shuffledFS{fstest.MapFS{
+		"a.txt":          sized(30, 1),
+		"b.txt":          sized(10, 3),
+		"c.txt":          sized(30, 2),
+		"dir1/file3.txt": sized(5, 1),
+		"dir1/file4.txt": sized(50, 1),
+		"dir1/skip/x":    sized(1, 1),
+	}}
+	tr := walker.New(testFS, ".", walker.OnErrorHalt)
+	tr.SortBy(func(a, b walker.Entry) int {
+		return walker.CompareBySize(b, a)
+	})
+	tr.ExcludeDir(walker.MatchGlobName("skip"))
+	for range 10 {
+		paths := slices.Collect(tr.Paths())
+		be.NilErr(t, tr.Err())
+		// Directories have size zero in fstest.MapFS.
+		be.Equal(t, ".; a.txt; c.txt; b.txt; dir1; dir1/file4.txt; dir1/file3.txt",
+			strings.Join(paths, "; "))
+	}
+
+	tr.SortBy(func(a, b walker.Entry) int {
+		return walker.CompareByModTime(b, a)
+	})
+	tr.Include(walker.MatchExtension(".txt"))
+	paths := slices.Collect(tr.FilePaths())
+	be.NilErr(t, tr.Err())
+	be.Equal(t, "b.txt; c.txt; a.txt; dir1/file3.txt; dir1/file4.txt", strings.Join(paths, "; "))
+}
+
+func TestRanger_BreadthFirst(t *testing.T) {
+	testFS := fstest.MapFS{
+		"a.txt":                &fstest.MapFile{},
+		"dir1/file3.txt":       &fstest.MapFile{},
+		"dir1/file4.log":       &fstest.MapFile{},
+		"dir2/file5.txt":       &fstest.MapFile{},
+		"dir2/subdir/file6.go": &fstest.MapFile{},
+		"file1.txt":            &fstest.MapFile{},
+		"file2.log":            &fstest.MapFile{},
+		"skip/file7.txt":       &fstest.MapFile{},
+	}
+	tr := walker.New(testFS, ".", walker.OnErrorHalt)
+	tr.BreadthFirst(true)
+	tr.ExcludeDir(walker.MatchGlobName("skip"))
+	paths := slices.Collect(tr.Paths())
+	be.NilErr(t, tr.Err())
+	be.Equal(t, ". a.txt dir1 dir2 file1.txt file2.log "+
+		"dir1/file3.txt dir1/file4.log dir2/file5.txt dir2/subdir "+
+		"dir2/subdir/file6.go", strings.Join(paths, " "))
+	depth := 0
+	for _, p := range paths {
+		d := strings.Count(p, "/")
+		be.True(t, d >= depth)
+		depth = d
+	}
+
+	var entered, left []string
+	tr.OnEnterDir(func(e walker.Entry) { entered = append(entered, e.Path) })
+	tr.OnLeaveDir(func(e walker.Entry) { left = append(left, e.Path) })
+	paths = nil
+	for e := range tr.Entries() {
+		if e.Path == "dir2" {
+			tr.SkipDir()
+		}
+		paths = append(paths, e.Path)
+	}
+	be.Equal(t, ". a.txt dir1 dir2 file1.txt file2.log dir1/file3.txt dir1/file4.log",
+		strings.Join(paths, " "))
+	be.Equal(t, ". dir1 dir2", strings.Join(entered, " "))
+	be.Equal(t, "dir2 dir1 .", strings.Join(left, " "))
+
+	testFS["bad/file8.txt"] = &fstest.MapFile{}
+	var errs []error
+	tr = walker.New(badDirFS{testFS}, ".", walker.OnErrorCollect(&errs))
+	tr.BreadthFirst(true)
+	tr.Include(walker.MatchExtension(".txt"))
+	paths = slices.Collect(tr.FilePaths())
+	be.Equal(t, "a.txt; file1.txt; dir1/file3.txt; dir2/file5.txt; skip/file7.txt",
+		strings.Join(paths, "; "))
+	be.Equal(t, 1, len(errs))
+	be.True(t, errors.Is(errs[0], fs.ErrPermission))
+
+	tr = walker.New(badDirFS{testFS}, ".", walker.OnErrorHalt)
+	tr.BreadthFirst(true)
+	paths = slices.Collect(tr.Paths())
+	be.True(t, errors.Is(tr.Err(), fs.ErrPermission))
+	be.Equal(t, "bad", tr.ErrPath())
+	// bad is read, and the walk halts, after the first level is yielded.
+	be.Equal(t, ". a.txt bad dir1 dir2 file1.txt file2.log skip", strings.Join(paths, " "))
+}
+
+// recordingFS is an fs.ReadDirFS which records how it was read.
+type recordingFS struct {
+	fstest.MapFS
+	readDirs, opens []string
+}
+
+func (fsys *recordingFS) ReadDir(name string) ([]fs.DirEntry, error) {
+	fsys.readDirs = append(fsys.readDirs, name)
+	return fsys.MapFS.ReadDir(name)
+}
+
+func (fsys *recordingFS) Open(name string) (fs.File, error) {
+	fsys.opens = append(fsys.opens, name)
+	return fsys.MapFS.Open(name)
+}
+
+func TestRanger_ReadDirFS(t *testing.T) {
+	testFS := &recordingFS{MapFS: fstest.MapFS{
+		"a.txt":                &fstest.MapFile{},
+		"dir1/file3.txt":       &fstest.MapFile{},
+		"dir2/subdir/file6.go": &fstest.MapFile{},
+	}}
+	tr := walker.New(testFS, ".", walker.OnErrorHalt)
+	paths := slices.Collect(tr.Paths())
+	be.NilErr(t, tr.Err())
+	be.Equal(t, 7, len(paths))
+	be.Equal(t, ". dir1 dir2 dir2/subdir", strings.Join(testFS.readDirs, " "))
+	be.Equal(t, 0, len(testFS.opens))
+}
+
+func BenchmarkRanger_ReadDirFS(b *testing.B) {
+	testFS := fstest.MapFS{}
+	for i := range 100 {
+		for j := range 20 {
+			testFS[fmt.Sprintf("dir%d/file%d.txt", i, j)] = &fstest.MapFile{}
+		}
+	}
+	b.Run("ReadDirFS", func(b *testing.B) {
+		tr := walker.New(testFS, ".", walker.OnErrorHalt)
+		for range b.N {
+			for range tr.Entries() {
+			}
+		}
+	})
+	b.Run("Open", func(b *testing.B) {
+		// Hide ReadDir so that directories must be opened and read.
+		openOnly := struct{ fs.FS }{testFS}
+		tr := walker.New(openOnly, ".", walker.OnErrorHalt)
+		for range b.N {
+			for range tr.Entries() {
+			}
+		}
+	})
+}